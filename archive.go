@@ -0,0 +1,260 @@
+package srtm
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoTileInArchive = errors.New("no SRTM tile in archive")
+var ErrAmbiguousArchive = errors.New("several SRTM tiles in archive")
+
+type compression int
+
+const (
+	uncompressed = compression(iota)
+	zipCompressed
+	gzipCompressed
+)
+
+// compressionOf returns the compression of a file based on its extension.
+func compressionOf(name string) compression {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".zip":
+		return zipCompressed
+	case ".gz":
+		return gzipCompressed
+	}
+	return uncompressed
+}
+
+// isHGTName returns true if the name of a file or archive entry refers to HGT data.
+func isHGTName(name string) bool {
+	switch compressionOf(name) {
+	case zipCompressed, gzipCompressed:
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return strings.EqualFold(filepath.Ext(name), ".hgt")
+}
+
+// Archive iterates over the tiles inside a zip or gzip archive.
+// Tiles are only decompressed when advancing to them.
+//
+// A typical loop looks like:
+//
+//	archive, err := srtm.OpenArchive("tiles.zip")
+//	...
+//	defer archive.Close()
+//	for archive.Next() {
+//		img := archive.Image()
+//		...
+//	}
+//	if err := archive.Err(); err != nil {
+//		...
+//	}
+type Archive struct {
+	closer  io.Closer
+	entries []func() (*SRTMImage, error)
+	img     *SRTMImage
+	err     error
+}
+
+// OpenArchive opens the zip (.zip) or gzip (.gz) archive with the given name.
+// Entries of zip archives are considered tiles if their name ends with .hgt.
+// Uncompressed HGT files are treated as an archive containing a single tile.
+func OpenArchive(name string) (*Archive, error) {
+	archive := &Archive{}
+	switch compressionOf(name) {
+	case zipCompressed:
+		r, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, err
+		}
+		archive.closer = r
+		files := zipTileFiles(&r.Reader)
+		for _, f := range files {
+			f := f
+			archive.entries = append(archive.entries, func() (*SRTMImage, error) {
+				return readZipFile(f, name, len(files) == 1)
+			})
+		}
+	case gzipCompressed:
+		archive.entries = append(archive.entries, func() (*SRTMImage, error) {
+			return openGzipImage(name)
+		})
+	default:
+		archive.entries = append(archive.entries, func() (*SRTMImage, error) {
+			return OpenSRTMImage(name)
+		})
+	}
+	return archive, nil
+}
+
+// Len returns the number of tiles in the archive.
+func (a *Archive) Len() int {
+	return len(a.entries)
+}
+
+// Next reads the next tile of the archive, which is then available through Image.
+// It returns false when there are no more tiles or an error occurred.
+func (a *Archive) Next() bool {
+	a.img = nil
+	if a.err != nil || len(a.entries) == 0 {
+		return false
+	}
+	a.img, a.err = a.entries[0]()
+	a.entries = a.entries[1:]
+	return a.err == nil
+}
+
+// Image returns the tile read by the last call to Next.
+func (a *Archive) Image() *SRTMImage {
+	return a.img
+}
+
+// Err returns the first error that occurred while reading the archive.
+func (a *Archive) Err() error {
+	return a.err
+}
+
+// Close closes the archive.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// zipTileFiles returns the entries of the zip archive holding HGT data.
+func zipTileFiles(r *zip.Reader) []*zip.File {
+	var files []*zip.File
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() && isHGTName(f.Name) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// openZipImage reads the tile from a zip archive.
+// If the archive holds several tiles, the one matching the name of the archive is read.
+func openZipImage(name string) (*SRTMImage, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := zipTileFiles(&r.Reader)
+	switch len(files) {
+	case 0:
+		return nil, fmt.Errorf("%w: %v", ErrNoTileInArchive, name)
+	case 1:
+		return readZipFile(files[0], name, true)
+	}
+
+	tile, err := ParseTile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAmbiguousArchive, name)
+	}
+	for _, f := range files {
+		if entryTile, err := ParseTile(path.Base(f.Name)); err == nil && entryTile == tile {
+			return readZipFile(f, name, false)
+		}
+	}
+	return nil, fmt.Errorf("%w: %v, tile: %v", ErrNoTileInArchive, name, tile)
+}
+
+// readZipFile reads the tile from an entry of a zip archive.
+// The tile is derived from the entry name. Only if the entry is the single tile of the archive,
+// the archive name serves as fallback, otherwise an unnamed entry returns ErrInvalidTile.
+func readZipFile(f *zip.File, archiveName string, single bool) (*SRTMImage, error) {
+	entryTile, entryErr := ParseTile(path.Base(f.Name))
+	if entryErr != nil && !single {
+		return nil, fmt.Errorf("%w: %v: entry %v of several has no tile name", ErrInvalidTile, archiveName, f.Name)
+	}
+
+	format, err := FormatFromZipFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", archiveName, err)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v: %w", archiveName, f.Name, err)
+	}
+	defer rc.Close()
+
+	img, err := Decoder{Name: archiveName + ": " + f.Name, Strict: true}.Decode(rc, format)
+	if err != nil {
+		return nil, err
+	}
+	if entryErr == nil {
+		img.setTile(entryTile)
+	} else if tile, err := ParseTile(archiveName); err == nil {
+		img.setTile(tile)
+	}
+	return img, nil
+}
+
+// openGzipImage reads the tile from a gzip compressed file.
+// The tile is derived from the original file name stored in the gzip header,
+// falling back to the name of the compressed file.
+func openGzipImage(name string) (*SRTMImage, error) {
+	format, err := gzipFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	defer zr.Close()
+
+	img, err := Decoder{Name: name, Strict: true}.Decode(zr, format)
+	if err != nil {
+		return nil, err
+	}
+	if tile, err := ParseTile(zr.Name); err == nil {
+		img.setTile(tile)
+	} else if tile, err := ParseTile(name); err == nil {
+		img.setTile(tile)
+	}
+	return img, nil
+}
+
+// gzipFormat detects the format of a gzip compressed HGT file from the
+// uncompressed size stored in the last four bytes of the file.
+// The size is stored modulo 2^32, which is unambiguous for the SRTM formats.
+func gzipFormat(name string) (SRTMFormat, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+
+	var size uint32
+	if _, err := f.Seek(-4, io.SeekEnd); err != nil {
+		return -1, fmt.Errorf("%v: %w", name, err)
+	}
+	if err := binary.Read(f, binary.LittleEndian, &size); err != nil {
+		return -1, fmt.Errorf("%v: %w", name, err)
+	}
+	format, err := FormatFromSize(int64(size))
+	if err != nil {
+		return -1, fmt.Errorf("%v: %w", name, err)
+	}
+	return format, nil
+}
@@ -0,0 +1,173 @@
+package srtm
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestZip writes a zip archive holding the images as .hgt entries.
+func writeTestZip(t *testing.T, name string, images ...*SRTMImage) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, img := range images {
+		entry, err := w.Create("tiles/" + img.Tile.Filename())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := binary.Write(entry, SRTMByteOrder, img.Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenSRTMImageZip(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "N48E012.SRTMGL3.hgt.zip")
+	writeTestZip(t, name,
+		newTestImage(Tile{48, 11}, func(x, y int) int16 { return 11 }),
+		newTestImage(Tile{48, 12}, func(x, y int) int16 { return 12 }))
+
+	img, err := OpenSRTMImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Tile != (Tile{48, 12}) || img.Format != SRTM3Format || img.Data[0] != 12 {
+		t.Error("OpenSRTMImage should read the tile matching the archive name, but read", img.Tile, img.Data[0])
+	}
+
+	ambiguous := filepath.Join(dir, "tiles.zip")
+	os.Rename(name, ambiguous)
+	_, err = OpenSRTMImage(ambiguous)
+	if errors.Is(err, ErrAmbiguousArchive) == false {
+		t.Error("OpenSRTMImage should return an ErrAmbiguousArchive error, but returned", err)
+	}
+}
+
+func TestOpenSRTMImageGzip(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := gzip.NewWriter(f)
+	w.Name = "S33W071.hgt"
+	binary.Write(w, SRTMByteOrder, newTestImage(Tile{}, func(x, y int) int16 { return int16(x) }).Data)
+	w.Close()
+	f.Close()
+
+	img, err := OpenSRTMImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Tile != (Tile{-33, -71}) || img.Format != SRTM3Format || img.Data[1200] != 1200 {
+		t.Error("OpenSRTMImage returned wrong image from gzip file", img.Tile, img.Format)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "tiles.zip")
+	writeTestZip(t, name,
+		newTestImage(Tile{48, 11}, func(x, y int) int16 { return 11 }),
+		newTestImage(Tile{48, 12}, func(x, y int) int16 { return 12 }))
+
+	archive, err := OpenArchive(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if archive.Len() != 2 {
+		t.Error("Len() should return 2, but returned", archive.Len())
+	}
+
+	var tiles []Tile
+	for archive.Next() {
+		img := archive.Image()
+		if int(img.Data[0]) != img.Tile.Lon {
+			t.Error("Image() returned wrong data for", img.Tile)
+		}
+		tiles = append(tiles, img.Tile)
+	}
+	if err := archive.Err(); err != nil {
+		t.Error("Err() should return nil, but returned", err)
+	}
+	if len(tiles) != 2 || tiles[0] != (Tile{48, 11}) || tiles[1] != (Tile{48, 12}) {
+		t.Error("archive should contain N48E011 and N48E012, but contained", tiles)
+	}
+}
+
+func TestArchiveEntryWithoutTileName(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, entries ...string) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		w := zip.NewWriter(f)
+		for _, entry := range entries {
+			ew, err := w.Create(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ew.Write(make([]byte, SRTM3Size*SRTM3Size*2)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	img, err := OpenSRTMImage(write("N48E012.hgt.zip", "data.hgt"))
+	if err != nil || !img.TileKnown || img.Tile != (Tile{48, 12}) {
+		t.Error("a single entry should take the tile from the archive name, but returned", err)
+	}
+
+	archive, err := OpenArchive(write("N48E013.hgt.zip", "N48E014.hgt", "data.hgt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if !archive.Next() || archive.Image().Tile != (Tile{48, 14}) {
+		t.Fatal("Next() should read N48E014, but returned", archive.Err())
+	}
+	if archive.Next() || !errors.Is(archive.Err(), ErrInvalidTile) {
+		t.Error("an entry without tile name among several should return an ErrInvalidTile error, but returned", archive.Err())
+	}
+}
+
+func TestDatasetArchives(t *testing.T) {
+	dir := t.TempDir()
+	writeTestZip(t, filepath.Join(dir, "tiles.zip"),
+		newTestImage(Tile{48, 11}, func(x, y int) int16 { return 11 }),
+		newTestImage(Tile{48, 12}, func(x, y int) int16 { return 12 }))
+
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := dataset.ElevationAt(48.5, 12.5, NearestNeighbor)
+	if err != nil || v != 12 {
+		t.Error("ElevationAt should read the zipped tile N48E012, but returned", v, err)
+	}
+	v, err = dataset.ElevationAt(48.5, 11.5, NearestNeighbor)
+	if err != nil || v != 11 {
+		t.Error("ElevationAt should read the zipped tile N48E011, but returned", v, err)
+	}
+}
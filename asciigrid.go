@@ -0,0 +1,149 @@
+package srtm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidASCIIGrid = errors.New("invalid ESRI ASCII grid")
+
+// EncodeASCIIGrid writes the elevation data as ESRI ASCII grid (.asc), one row of samples per line
+// from north to south. The cells are centered on the samples, so xllcorner and yllcorner lie half
+// a cell south west of the south west corner of the tile. Voids are written as NODATA_value -32768.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) EncodeASCIIGrid(w io.Writer) error {
+	size := srtmImg.Format.Size()
+	if size < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidFormat, srtmImg.Format)
+	}
+	width, height := srtmImg.Width(), srtmImg.Height()
+	// larger mosaics could not be read again
+	if err := checkMosaicSize(srtmImg.Format, atLeastOne(srtmImg.Columns), atLeastOne(srtmImg.Rows)); err != nil {
+		return err
+	}
+	if len(srtmImg.Data) != width*height {
+		return fmt.Errorf("%w: %v, length: %v", ErrInvalidDataLength, srtmImg.Format, len(srtmImg.Data))
+	}
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+
+	cellSize := 1 / float64(size-1)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ncols %d\nnrows %d\n", width, height)
+	fmt.Fprintf(bw, "xllcorner %s\nyllcorner %s\n", formatCoordinate(float64(srtmImg.Tile.Lon)-cellSize/2), formatCoordinate(float64(srtmImg.Tile.Lat)-cellSize/2))
+	fmt.Fprintf(bw, "cellsize %s\nNODATA_value %d\n", formatCoordinate(cellSize), VoidValue)
+
+	var line []byte
+	for y := 0; y < height; y++ {
+		line = line[:0]
+		for x, v := range srtmImg.Data[y*width : (y+1)*width] {
+			if x > 0 {
+				line = append(line, ' ')
+			}
+			line = strconv.AppendInt(line, int64(v), 10)
+		}
+		line = append(line, '\n')
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodeASCIIGrid reads an ESRI ASCII grid (.asc) in WGS84 coordinates, accepting the corner
+// as well as the center variants of the header and GDAL's dx and dy for non square cells.
+//
+// Like DecodeGeoTIFF, the elevations are resampled onto the SRTM grid of the tiles the grid covers,
+// grids written by EncodeASCIIGrid are read exactly. Samples outside of the grid and its NODATA_value are voids.
+func DecodeASCIIGrid(r io.Reader) (*SRTMImage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	header := map[string]float64{}
+	var first string
+	for scanner.Scan() {
+		key := strings.ToLower(scanner.Text())
+		if _, err := strconv.ParseFloat(key, 64); err == nil {
+			// the header ends with the first sample
+			first = key
+			break
+		}
+		if !scanner.Scan() {
+			break
+		}
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v: %v", ErrInvalidASCIIGrid, key, err)
+		}
+		header[key] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	g := &raster{width: int(header["ncols"]), height: int(header["nrows"])}
+	if g.width <= 0 || g.height <= 0 {
+		return nil, fmt.Errorf("%w: grid size %vx%v", ErrInvalidASCIIGrid, header["ncols"], header["nrows"])
+	}
+	if err := checkRasterSize(g.width, g.height, ErrInvalidASCIIGrid); err != nil {
+		return nil, err
+	}
+	g.scaleX, g.scaleY = header["cellsize"], header["cellsize"]
+	if dx, ok := header["dx"]; ok {
+		g.scaleX, g.scaleY = dx, header["dy"]
+	}
+	if !(g.scaleX > 0) || !(g.scaleY > 0) {
+		return nil, fmt.Errorf("%w: cell size %v x %v", ErrInvalidASCIIGrid, g.scaleX, g.scaleY)
+	}
+	west, okWest := header["xllcenter"]
+	south, okSouth := header["yllcenter"]
+	if corner, ok := header["xllcorner"]; ok {
+		west, okWest = corner+g.scaleX/2, true
+	}
+	if corner, ok := header["yllcorner"]; ok {
+		south, okSouth = corner+g.scaleY/2, true
+	}
+	if !okWest || !okSouth {
+		return nil, fmt.Errorf("%w: missing lower left corner", ErrInvalidASCIIGrid)
+	}
+	if west < -180 || west > 180 || south < -90 || south > 90 {
+		return nil, fmt.Errorf("%w: lower left corner %v/%v is not in WGS84 coordinates", ErrInvalidASCIIGrid, south, west)
+	}
+	g.west, g.north = west, south+float64(g.height-1)*g.scaleY
+	noData, ok := header["nodata_value"]
+	if !ok {
+		noData = math.NaN()
+	}
+
+	g.data = make([]int16, g.width*g.height)
+	for i := range g.data {
+		token := first
+		if i > 0 {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("%w: %v samples instead of %v", ErrInvalidASCIIGrid, i, len(g.data))
+			}
+			token = scanner.Text()
+		}
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: sample %v: %v", ErrInvalidASCIIGrid, i, err)
+		}
+		g.data[i] = rasterElevation(v, noData)
+	}
+	return g.resample(ErrInvalidASCIIGrid)
+}
+
+// formatCoordinate formats a coordinate with the precision needed to read it back exactly.
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,62 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeASCIIGrid(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x - y) })
+	img.Data[1201*5+7] = VoidValue
+
+	var buf bytes.Buffer
+	if err := img.EncodeASCIIGrid(&buf); err != nil {
+		t.Fatal(err)
+	}
+	header := "ncols 1201\nnrows 1201\nxllcorner 11.999583333333334\nyllcorner 47.999583333333334\ncellsize 0.0008333333333333334\nNODATA_value -32768\n0 1 2 "
+	if !strings.HasPrefix(buf.String(), header) {
+		t.Error("ASCII grid should start with", header, "but started with", buf.String()[:len(header)])
+	}
+
+	decoded, err := DecodeASCIIGrid(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(img, decoded) || decoded.IsMosaic() {
+		t.Error("ASCII grid should decode to the encoded image, but returned", decoded.Format, decoded.Tile)
+	}
+}
+
+func TestDecodeASCIIGrid(t *testing.T) {
+	// 3x2 cells of 3 arc seconds centered on the north west corner of N48E012
+	grid := "NCOLS 3\nNROWS 2\nXLLCENTER 12\nYLLCENTER 48.99916666666667\nCELLSIZE 0.0008333333333333334\nNODATA_VALUE -9999\n" +
+		"100.4 101.6 -9999\n103 104 105\n"
+	img, err := DecodeASCIIGrid(strings.NewReader(grid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM3Format || img.Tile != (Tile{48, 12}) {
+		t.Fatal("ASCII grid should cover the SRTM3 tile N48E012, but returned", img.Format, img.Tile)
+	}
+	for _, p := range []struct {
+		x, y      int
+		elevation int16
+	}{{0, 0, 100}, {1, 0, 102}, {2, 0, VoidValue}, {2, 1, 105}, {3, 0, VoidValue}, {0, 2, VoidValue}} {
+		if e := img.Data[p.y*img.Width()+p.x]; e != p.elevation {
+			t.Error("elevation at", p.x, p.y, "should be", p.elevation, "but was", e)
+		}
+	}
+
+	for _, invalid := range []string{
+		"ncols 3\nnrows 2\nxllcorner 12\nyllcorner 48\ncellsize 0.001\n1 2 3 4 5\n",
+		"ncols 3\nnrows 2\nxllcorner 12\nyllcorner 48\n1 2 3 4 5 6\n",
+		"ncols 3\nnrows 2\nxllcorner 500000\nyllcorner 5300000\ncellsize 30\n1 2 3 4 5 6\n",
+		"ncols 3\nnrows 2\nxllcorner 12\nyllcorner 48\ncellsize 0.001\n1 2 x 4 5 6\n",
+	} {
+		if _, err := DecodeASCIIGrid(strings.NewReader(invalid)); !errors.Is(err, ErrInvalidASCIIGrid) {
+			t.Errorf("%q should return an ErrInvalidASCIIGrid error, but returned %v", invalid, err)
+		}
+	}
+}
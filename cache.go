@@ -0,0 +1,159 @@
+package srtm
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrLoadPanicked = errors.New("loading SRTM tile panicked")
+
+// TileCache keeps decoded tiles in memory up to a budget of bytes,
+// evicting the least recently used tiles first.
+// Concurrent requests for the same tile share a single call of the load function.
+// A TileCache is safe for concurrent use.
+type TileCache struct {
+	load func(Tile) (*SRTMImage, error)
+
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	lru      *list.List // of *cacheEntry, most recently used at the front
+	entries  map[Tile]*list.Element
+	inflight map[Tile]*cacheCall
+	stats    CacheStats
+}
+
+// CacheStats are the counters of a TileCache.
+type CacheStats struct {
+	Hits      uint64 // requests served from memory, including those waiting for a concurrent load
+	Misses    uint64 // requests which loaded the tile
+	Evictions uint64 // tiles removed to stay within the budget
+	Tiles     int    // tiles currently cached
+	Bytes     int64  // bytes currently used by the cached tiles
+}
+
+type cacheEntry struct {
+	tile Tile
+	img  *SRTMImage
+}
+
+type cacheCall struct {
+	wg  sync.WaitGroup
+	img *SRTMImage
+	err error
+}
+
+// NewTileCache returns a cache which loads missing tiles with the given function.
+// A maxBytes of 0 or less does not limit the cache.
+func NewTileCache(maxBytes int64, load func(Tile) (*SRTMImage, error)) *TileCache {
+	return &TileCache{
+		load:     load,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[Tile]*list.Element),
+		inflight: make(map[Tile]*cacheCall),
+	}
+}
+
+// Get returns the tile from the cache or loads it.
+// Failed loads are not cached. If the load function panics, the panic propagates
+// to the caller and concurrent requests for the tile return ErrLoadPanicked.
+func (c *TileCache) Get(tile Tile) (*SRTMImage, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[tile]; ok {
+		c.lru.MoveToFront(elem)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return elem.Value.(*cacheEntry).img, nil
+	}
+	if call, ok := c.inflight[tile]; ok {
+		c.stats.Hits++
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.img, call.err
+	}
+	call := new(cacheCall)
+	call.wg.Add(1)
+	c.inflight[tile] = call
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	c.loadCall(tile, call)
+	return call.img, call.err
+}
+
+// loadCall loads the tile for the call and releases the requests waiting for it,
+// even if the load function panics.
+func (c *TileCache) loadCall(tile Tile, call *cacheCall) {
+	call.err = fmt.Errorf("%w: %v", ErrLoadPanicked, tile)
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, tile)
+		if call.err == nil {
+			c.add(tile, call.img)
+		}
+		c.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.img, call.err = c.load(tile)
+}
+
+// add inserts the image and evicts tiles until the budget is met.
+// Images which exceed the budget on their own are not cached at all.
+// The caller must hold c.mu.
+func (c *TileCache) add(tile Tile, img *SRTMImage) {
+	size := imageBytes(img)
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+	c.entries[tile] = c.lru.PushFront(&cacheEntry{tile, img})
+	c.bytes += size
+	c.evict()
+}
+
+// evict removes the least recently used tiles until the budget is met.
+// The caller must hold c.mu.
+func (c *TileCache) evict() {
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		elem := c.lru.Back()
+		entry := c.lru.Remove(elem).(*cacheEntry)
+		delete(c.entries, entry.tile)
+		c.bytes -= imageBytes(entry.img)
+		c.stats.Evictions++
+	}
+}
+
+// SetMaxBytes changes the budget of the cache, evicting tiles if necessary.
+// A maxBytes of 0 or less does not limit the cache.
+func (c *TileCache) SetMaxBytes(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxBytes = maxBytes
+	c.evict()
+}
+
+// Purge removes all tiles from the cache. The counters are kept.
+func (c *TileCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[Tile]*list.Element)
+	c.bytes = 0
+}
+
+// Stats returns a snapshot of the counters of the cache.
+func (c *TileCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Tiles = c.lru.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// imageBytes returns the memory used by the elevation data of the image.
+func imageBytes(img *SRTMImage) int64 {
+	return int64(len(img.Data)) * 2
+}
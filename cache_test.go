@@ -0,0 +1,145 @@
+package srtm
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// smallImage returns an image whose data uses exactly 2*n bytes, regardless of the format.
+func smallImage(tile Tile, n int) *SRTMImage {
+	return &SRTMImage{Data: make([]int16, n), Format: SRTM3Format, Tile: tile, TileKnown: true}
+}
+
+func TestTileCacheLRU(t *testing.T) {
+	var loads int32
+	cache := NewTileCache(300, func(tile Tile) (*SRTMImage, error) {
+		atomic.AddInt32(&loads, 1)
+		return smallImage(tile, 50), nil
+	})
+
+	cache.Get(Tile{0, 0})
+	cache.Get(Tile{0, 1})
+	cache.Get(Tile{0, 2})
+	// touch the first tile, so the second one is the least recently used
+	cache.Get(Tile{0, 0})
+	cache.Get(Tile{0, 3})
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Evictions != 1 || stats.Tiles != 3 || stats.Bytes != 300 {
+		t.Error("unexpected stats after eviction:", stats)
+	}
+	cache.Get(Tile{0, 0})
+	cache.Get(Tile{0, 1})
+	if loads != 5 {
+		t.Error("Tile{0,1} should have been evicted and reloaded, but loads was", loads)
+	}
+
+	cache.SetMaxBytes(100)
+	if stats := cache.Stats(); stats.Tiles != 1 || stats.Bytes != 100 {
+		t.Error("SetMaxBytes(100) should leave one tile, but stats were", stats)
+	}
+}
+
+func TestTileCacheTooLarge(t *testing.T) {
+	cache := NewTileCache(10, func(tile Tile) (*SRTMImage, error) {
+		return smallImage(tile, 50), nil
+	})
+	img, err := cache.Get(Tile{0, 0})
+	if img == nil || err != nil {
+		t.Error("Get should return tiles exceeding the budget, but returned", img, err)
+	}
+	if stats := cache.Stats(); stats.Tiles != 0 {
+		t.Error("tiles exceeding the budget should not be cached, but stats were", stats)
+	}
+}
+
+func TestTileCacheError(t *testing.T) {
+	errLoad := errors.New("load failed")
+	cache := NewTileCache(0, func(tile Tile) (*SRTMImage, error) {
+		return nil, errLoad
+	})
+	_, err := cache.Get(Tile{0, 0})
+	if errors.Is(err, errLoad) == false {
+		t.Error("Get should return the load error, but returned", err)
+	}
+	if stats := cache.Stats(); stats.Tiles != 0 {
+		t.Error("failed loads should not be cached, but stats were", stats)
+	}
+}
+
+func TestTileCacheConcurrent(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := NewTileCache(0, func(tile Tile) (*SRTMImage, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return smallImage(tile, 1), nil
+	})
+
+	var wg sync.WaitGroup
+	images := make([]*SRTMImage, 16)
+	for i := range images {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			images[i], _ = cache.Get(Tile{48, 12})
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Error("concurrent Get should load the tile once, but loaded", loads)
+	}
+	for _, img := range images {
+		if img != images[0] {
+			t.Fatal("concurrent Get should return the same image")
+		}
+	}
+	if stats := cache.Stats(); stats.Hits+stats.Misses != 16 || stats.Misses != 1 {
+		t.Error("unexpected stats after concurrent Get:", stats)
+	}
+}
+
+func TestTileCachePanic(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	cache := NewTileCache(0, func(tile Tile) (*SRTMImage, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+			panic("corrupt tile")
+		}
+		return smallImage(tile, 10), nil
+	})
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		cache.Get(Tile{1, 1})
+	}()
+	<-started
+	waiter := make(chan error)
+	go func() {
+		_, err := cache.Get(Tile{1, 1})
+		waiter <- err
+	}()
+	// give the waiter time to join the inflight load
+	for cache.Stats().Hits == 0 {
+		runtime.Gosched()
+	}
+	close(release)
+
+	if r := <-panicked; r == nil {
+		t.Error("the panic of the load function should propagate to the caller")
+	}
+	if err := <-waiter; !errors.Is(err, ErrLoadPanicked) {
+		t.Error("a concurrent Get should return an ErrLoadPanicked error, but returned", err)
+	}
+	if img, err := cache.Get(Tile{1, 1}); err != nil || img == nil {
+		t.Error("Get after a panic should load the tile again, but returned", err)
+	}
+}
@@ -1,57 +1,149 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"image"
 	"image/png"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/schicho/srtm"
 )
 
 func main() {
-	f, err := os.Open(os.Args[1])
-	if err != nil {
-		log.Println(err)
+	mode := flag.String("mode", "scaled", "output mode: scaled, hillshade, multidirectional, combined or color")
+	azimuth := flag.Float64("azimuth", srtm.DefaultHillshadeOptions.Azimuth, "hillshade: sun azimuth in degrees clockwise from north")
+	altitude := flag.Float64("altitude", srtm.DefaultHillshadeOptions.Altitude, "hillshade: sun altitude in degrees above the horizon")
+	zFactor := flag.Float64("z", srtm.DefaultHillshadeOptions.ZFactor, "hillshade: vertical exaggeration")
+	paletteName := flag.String("palette", "hypsometric", "color: builtin palette ("+strings.Join(srtm.BuiltinPaletteNames, ", ")+") or GDAL color-relief file")
+	from := flag.String("from", "", "directory: south-western tile of the mosaic, e.g. N47E010")
+	to := flag.String("to", "", "directory: north-eastern tile of the mosaic, e.g. N48E012")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Println("usage: srtm2png [flags] <file or directory>")
+		log.Println("a directory is rendered as mosaic of the tiles from -from to -to,")
+		log.Println("by default of all tiles inside of it, if they fill their bounding box")
+		flag.PrintDefaults()
 		os.Exit(1)
 	}
-	defer f.Close()
 
-	var format srtm.SRTMFormat
-	stat, err := f.Stat()
+	srtmImg, err := openImage(flag.Arg(0), *from, *to)
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
-	switch stat.Size() {
-	case 25934402:
-		log.Println("Assuming SRTM1 format")
-		format = srtm.SRTM1Format
-	case 2884802:
-		log.Println("assuming SRTM3 format")
-		format = srtm.SRTM3Format
+	log.Println("detected", srtmImg.Format, "format")
+
+	options := srtm.DefaultHillshadeOptions
+	options.Azimuth = *azimuth
+	options.Altitude = *altitude
+	options.ZFactor = *zFactor
+
+	var img image.Image
+	suffix := "-out-8.png"
+	switch *mode {
+	case "scaled":
+		p4 := srtmImg.ElevationPercentile(0.04)
+		p95 := srtmImg.ElevationPercentile(0.95)
+		img = srtmImg.ScaledHeightImage(2, int16((p4+p95)/2))
+	case "hillshade":
+		img, err = srtmImg.HillshadeImage(options)
+		suffix = "-out-hillshade.png"
+	case "multidirectional":
+		img, err = srtmImg.MultidirectionalHillshadeImage(options)
+		suffix = "-out-hillshade.png"
+	case "combined":
+		img, err = srtmImg.CombinedHillshadeImage(options)
+		suffix = "-out-hillshade.png"
+	case "color":
+		palette, err := srtm.BuiltinPalette(*paletteName)
+		if err != nil {
+			palette, err = srtm.LoadPalette(*paletteName)
+		}
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		img = srtmImg.ColorReliefImage(palette)
+		suffix = "-out-color.png"
 	default:
-		log.Println("unknown filesize. exiting")
+		log.Println("unknown mode:", *mode)
 		os.Exit(1)
 	}
-
-	srtmImg, err := srtm.NewSRTMImage(f, format)
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
 
-	f_out, err := os.Create(stat.Name() + "-out-8.png")
+	f_out, err := os.Create(filepath.Base(flag.Arg(0)) + suffix)
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
 	defer f_out.Close()
 
-	p4 := srtmImg.ElevationPercentile(0.04)
-	p95 := srtmImg.ElevationPercentile(0.95)
-	err = png.Encode(f_out, srtmImg.ScaledHeightImage(2, int16((p4+p95)/2)))
+	err = png.Encode(f_out, img)
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
 }
+
+// openImage reads a single tile, or a mosaic if name is a directory.
+// The mosaic spans the tiles from and to, or the bounding box of all tiles if both are empty.
+func openImage(name, from, to string) (*srtm.SRTMImage, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return srtm.OpenSRTMImage(name)
+	}
+
+	dataset, err := srtm.OpenDataset(name)
+	if err != nil {
+		return nil, err
+	}
+	if from != "" || to != "" {
+		southWest, err := srtm.ParseTile(from)
+		if err != nil {
+			return nil, err
+		}
+		northEast, err := srtm.ParseTile(to)
+		if err != nil {
+			return nil, err
+		}
+		return dataset.Mosaic(southWest, northEast.Lon-southWest.Lon+1, northEast.Lat-southWest.Lat+1)
+	}
+
+	tiles := dataset.Tiles()
+	if len(tiles) == 0 {
+		return nil, srtm.ErrEmptyMosaic
+	}
+	southWest, northEast := tiles[0], tiles[0]
+	for _, tile := range tiles {
+		if tile.Lat < southWest.Lat {
+			southWest.Lat = tile.Lat
+		}
+		if tile.Lat > northEast.Lat {
+			northEast.Lat = tile.Lat
+		}
+		if tile.Lon < southWest.Lon {
+			southWest.Lon = tile.Lon
+		}
+		if tile.Lon > northEast.Lon {
+			northEast.Lon = tile.Lon
+		}
+	}
+	columns, rows := northEast.Lon-southWest.Lon+1, northEast.Lat-southWest.Lat+1
+	// a sparse directory would mostly render voids, possibly exceeding the memory
+	if len(tiles) < columns*rows {
+		return nil, fmt.Errorf("%v: %v tiles do not fill their bounding box from %v to %v, select the mosaic with -from and -to",
+			name, len(tiles), southWest, northEast)
+	}
+	log.Println("mosaic of", len(tiles), "tiles")
+	return dataset.Mosaic(southWest, columns, rows)
+}
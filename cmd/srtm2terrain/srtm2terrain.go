@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/schicho/srtm"
+)
+
+func main() {
+	dataDir := flag.String("data", ".", "directory containing the SRTM tiles")
+	encodingName := flag.String("encoding", "mapbox", "encoding: mapbox (Terrain-RGB) or terrarium")
+	base := flag.Float64("base", 0, "elevation of black in meters (default the base of the encoding)")
+	interval := flag.Float64("interval", 0, "elevation step in meters (default the interval of the encoding)")
+	minZoom := flag.Int("minzoom", 5, "lowest zoom level to render")
+	maxZoom := flag.Int("maxzoom", 12, "highest zoom level to render")
+	size := flag.Int("size", 256, "width and height of the tiles in pixels")
+	output := flag.String("o", "terrain", "output directory, receiving the tiles as <zoom>/<x>/<y>.png")
+	flag.Parse()
+	if flag.NArg() != 0 || *minZoom < 0 || *maxZoom < *minZoom {
+		log.Println("usage: srtm2terrain [flags]")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	var encoding srtm.RGBEncoding
+	switch *encodingName {
+	case "mapbox":
+		encoding = srtm.TerrainRGB
+	case "terrarium":
+		encoding = srtm.Terrarium
+	default:
+		log.Println("unknown encoding:", *encodingName)
+		os.Exit(1)
+	}
+	// -base and -interval override the encoding independently of each other
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "base":
+			encoding.Base = *base
+		case "interval":
+			encoding.Interval = *interval
+		}
+	})
+
+	dataset, err := srtm.OpenDataset(*dataDir)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+
+	for zoom := *minZoom; zoom <= *maxZoom; zoom++ {
+		// the web tiles covering any of the SRTM tiles
+		webTiles := map[[2]int]bool{}
+		for _, tile := range dataset.Tiles() {
+			// stay clear of the east and south edges, which belong to the neighbouring web tiles
+			west, north := srtm.WebMercatorTileAt(float64(tile.Lat+1), float64(tile.Lon), zoom)
+			east, south := srtm.WebMercatorTileAt(float64(tile.Lat)+1e-9, float64(tile.Lon+1)-1e-9, zoom)
+			for x := west; x <= east; x++ {
+				for y := north; y <= south; y++ {
+					webTiles[[2]int{x, y}] = true
+				}
+			}
+		}
+
+		for webTile := range webTiles {
+			x, y := webTile[0], webTile[1]
+			grid, err := dataset.WebMercatorTile(zoom, x, y, *size, srtm.Bilinear)
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			img, err := grid.RGBElevationImage(encoding)
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			if err := writePNG(*output, zoom, x, y, img); err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+		}
+		log.Printf("zoom %d: %d tiles", zoom, len(webTiles))
+	}
+}
+
+// writePNG writes img as dir/zoom/x/y.png.
+func writePNG(dir string, zoom, x, y int, img image.Image) error {
+	name := filepath.Join(dir, fmt.Sprint(zoom), fmt.Sprint(x), fmt.Sprint(y)+".png")
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f_out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f_out, img); err != nil {
+		f_out.Close()
+		return err
+	}
+	return f_out.Close()
+}
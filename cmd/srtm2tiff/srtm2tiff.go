@@ -1,56 +1,43 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/schicho/srtm"
-	"golang.org/x/image/tiff"
 )
 
 func main() {
-	f, err := os.Open(os.Args[1])
-	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	defer f.Close()
-
-	var format srtm.SRTMFormat
-	stat, err := f.Stat()
-	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	switch stat.Size() {
-	case 25934402:
-		log.Println("Assuming SRTM1 format")
-		format = srtm.SRTM1Format
-	case 2884802:
-		log.Println("assuming SRTM3 format")
-		format = srtm.SRTM3Format
-	default:
-		log.Println("unknown filesize. exiting")
+	deflate := flag.Bool("deflate", true, "compress the data with deflate")
+	tileSize := flag.Int("tile", 0, "write square tiles of the given size, a multiple of 16, instead of strips")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Println("usage: srtm2tiff [flags] <file>")
+		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	srtmImg, err := srtm.NewSRTMImage(f, format)
+	srtmImg, err := srtm.OpenSRTMImage(flag.Arg(0))
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
+	log.Println("detected", srtmImg.Format, "format")
 
-	f_out, err := os.Create(stat.Name() + "-out-16.tiff")
+	// encode before creating the file, so a failure leaves no empty file behind
+	var buf bytes.Buffer
+	err = srtmImg.EncodeGeoTIFF(&buf, srtm.GeoTIFFOptions{Deflate: *deflate, TileSize: *tileSize})
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
-	defer f_out.Close()
 
-	err = tiff.Encode(f_out, srtmImg.FullImage(), nil)
+	err = os.WriteFile(filepath.Base(flag.Arg(0))+".tif", buf.Bytes(), 0644)
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
-	log.Println("Please note that the bit-depth is 16 bit per pixel.\nSome viewers may not be able to display the image.")
 }
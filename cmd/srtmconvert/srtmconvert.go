@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/schicho/srtm"
+)
+
+func main() {
+	format := flag.String("format", "asc", "format of standard input and output: asc, xyz or tif")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		log.Println("usage: srtmconvert [flags] <input> <output>")
+		log.Println("the formats are taken from the file extensions: .hgt (.zip, .gz), .tif, .asc or .xyz")
+		log.Println("DTED files (.dt0, .dt1, .dt2) can be read as input")
+		log.Println("- reads from standard input or writes to standard output in the format given by -format")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	var decode func(io.Reader) (*srtm.SRTMImage, error)
+	var encode func(*srtm.SRTMImage, io.Writer) error
+	switch *format {
+	case "asc":
+		decode, encode = srtm.DecodeASCIIGrid, (*srtm.SRTMImage).EncodeASCIIGrid
+	case "xyz":
+		decode, encode = srtm.DecodeXYZ, (*srtm.SRTMImage).EncodeXYZ
+	case "tif":
+		decode = srtm.DecodeGeoTIFF
+		encode = func(srtmImg *srtm.SRTMImage, w io.Writer) error {
+			return srtmImg.EncodeGeoTIFF(w, srtm.GeoTIFFOptions{Deflate: true})
+		}
+	default:
+		log.Println("unknown format:", *format)
+		os.Exit(1)
+	}
+
+	var srtmImg *srtm.SRTMImage
+	var err error
+	if flag.Arg(0) == "-" {
+		srtmImg, err = decode(bufio.NewReader(os.Stdin))
+	} else {
+		srtmImg, err = srtm.OpenSRTMImage(flag.Arg(0))
+	}
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	log.Println("detected", srtmImg.Format, "format covering", srtmImg.Width(), "x", srtmImg.Height(), "samples from", srtmImg.Tile)
+
+	if flag.Arg(1) == "-" {
+		err = encode(srtmImg, os.Stdout)
+	} else {
+		err = srtmImg.WriteFile(flag.Arg(1))
+	}
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
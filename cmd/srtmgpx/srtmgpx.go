@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/schicho/srtm"
+)
+
+func main() {
+	dataDir := flag.String("data", ".", "directory containing the SRTM tiles")
+	interpolation := flag.String("interpolation", "bilinear", "interpolation: nearest, bilinear or bicubic")
+	smoothing := flag.Float64("smooth", 0, "length of the moving average smoothing the elevations in meters, 0 disables smoothing")
+	output := flag.String("o", "", "output file, standard output by default")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Println("usage: srtmgpx [flags] <file.gpx>")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	options := srtm.GPXOptions{Smoothing: *smoothing}
+	switch *interpolation {
+	case "nearest":
+		options.Interpolation = srtm.NearestNeighbor
+	case "bilinear":
+		options.Interpolation = srtm.Bilinear
+	case "bicubic":
+		options.Interpolation = srtm.Bicubic
+	default:
+		log.Println("unknown interpolation:", *interpolation)
+		os.Exit(1)
+	}
+
+	dataset, err := srtm.OpenDataset(*dataDir)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+
+	f_in, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	defer f_in.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f_out, err := os.Create(*output)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		defer f_out.Close()
+		w = f_out
+	}
+
+	summary, err := dataset.EnrichGPX(w, f_in, options)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	log.Printf("corrected %d of %d points", summary.Corrected, summary.Points)
+	log.Printf("before: ascent %.0f m, descent %.0f m", summary.Before.Ascent, summary.Before.Descent)
+	log.Printf("after:  ascent %.0f m, descent %.0f m", summary.After.Ascent, summary.After.Descent)
+}
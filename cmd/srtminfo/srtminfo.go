@@ -8,38 +8,16 @@ import (
 )
 
 func main() {
-	f, err := os.Open(os.Args[1])
+	srtm, err := srtm.OpenSRTMImage(os.Args[1])
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	defer f.Close()
-
-	stat, err := f.Stat()
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	fmt.Println("detected", srtm.Format, "format")
+	if srtm.TileKnown {
+		fmt.Println("tile:", srtm.Tile)
 	}
 
-	var format srtm.SRTMFormat
-	fmt.Printf("file size is %d bytes\n", stat.Size())
-	switch stat.Size() {
-	case 25934402:
-		fmt.Println("Assuming SRTM1 format")
-		format = srtm.SRTM1Format
-	case 2884802:
-		fmt.Println("assuming SRTM3 format")
-		format = srtm.SRTM3Format
-	default:
-		fmt.Println("unknown filesize. exiting")
-		os.Exit(1)
-	}
-
-	srtm, err := srtm.NewSRTMImage(f, format)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
 	min, max := srtm.ElevationMinMax()
 	mean := srtm.ElevationMean()
 	countDatavoids := len(srtm.ElevationVoids())
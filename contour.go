@@ -0,0 +1,311 @@
+package srtm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+var ErrInvalidContourInterval = errors.New("contour interval must be positive")
+
+// ContourOptions configure Contours.
+type ContourOptions struct {
+	// Interval is the elevation difference between two contour lines in meters.
+	Interval float64
+	// Base is the elevation the intervals are counted from, 0 by default.
+	Base float64
+	// IndexInterval marks every contour line at a multiple of it as index contour,
+	// e.g. every 100 meters. 0 disables index contours.
+	IndexInterval float64
+	// Smoothing is the number of iterations of Chaikin's corner cutting applied to the lines.
+	// Each iteration doubles the number of points.
+	Smoothing int
+}
+
+// Contour is a line of constant elevation in fractional sample coordinates.
+type Contour struct {
+	Elevation float64
+	Index     bool
+	// Closed lines start and end with the same point.
+	Closed bool
+	Points []PointF
+}
+
+// contourSegment is the part of a contour line crossing a single cell of four samples.
+// The ends are identified by the cell edge they lie on, so that segments can be joined exactly.
+type contourSegment struct {
+	edges  [2]int
+	points [2]PointF
+}
+
+// Contours traces lines of constant elevation using marching squares.
+// Cells touching a void are skipped, so lines end at voids just like at the tile edges.
+// The returned lines are sorted by elevation.
+func (srtmImg *SRTMImage) Contours(options ContourOptions) ([]Contour, error) {
+	if !(options.Interval > 0) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContourInterval, options.Interval)
+	}
+
+	width, height := srtmImg.Width(), srtmImg.Height()
+	// segments by level, with level k being at Base + k*Interval
+	segments := make(map[int][]contourSegment)
+	for y := 0; y < height-1; y++ {
+		for x := 0; x < width-1; x++ {
+			corners := [4]int16{
+				srtmImg.Data[y*width+x], srtmImg.Data[y*width+x+1],
+				srtmImg.Data[(y+1)*width+x+1], srtmImg.Data[(y+1)*width+x],
+			}
+			lo, hi := corners[0], corners[0]
+			void := false
+			for _, v := range corners {
+				if v == VoidValue {
+					void = true
+				}
+				if v < lo {
+					lo = v
+				}
+				if v > hi {
+					hi = v
+				}
+			}
+			if void {
+				continue
+			}
+			// levels l with lo < l <= hi cross the cell
+			first := int(math.Floor((float64(lo)-options.Base)/options.Interval)) + 1
+			last := int(math.Floor((float64(hi) - options.Base) / options.Interval))
+			for k := first; k <= last; k++ {
+				level := options.Base + float64(k)*options.Interval
+				segments[k] = appendCellSegments(segments[k], corners, level, x, y, width)
+			}
+		}
+	}
+
+	levels := make([]int, 0, len(segments))
+	for k := range segments {
+		levels = append(levels, k)
+	}
+	sort.Ints(levels)
+
+	var contours []Contour
+	for _, k := range levels {
+		level := options.Base + float64(k)*options.Interval
+		index := false
+		if options.IndexInterval > 0 {
+			// multiples of IndexInterval, independent of Base
+			remainder := math.Abs(math.Mod(level, options.IndexInterval))
+			index = remainder < 1e-9 || math.Abs(remainder-options.IndexInterval) < 1e-9
+		}
+		for _, line := range stitchSegments(segments[k]) {
+			contour := Contour{Elevation: level, Index: index, Points: line}
+			contour.Closed = len(line) > 2 && line[0] == line[len(line)-1]
+			for i := 0; i < options.Smoothing; i++ {
+				contour.Points = chaikin(contour.Points, contour.Closed)
+			}
+			contours = append(contours, contour)
+		}
+	}
+	return contours, nil
+}
+
+// appendCellSegments appends the segments of the level crossing the cell with the top left sample x,y.
+// The corners are ordered clockwise starting at the top left.
+func appendCellSegments(segments []contourSegment, corners [4]int16, level float64, x, y, width int) []contourSegment {
+	// edges of the cell, ordered top, right, bottom, left, each from one corner to the next clockwise
+	edgeIDs := [4]int{
+		2 * (y*width + x),
+		2*(y*width+x+1) + 1,
+		2 * ((y+1)*width + x),
+		2*(y*width+x) + 1,
+	}
+	positions := [4]PointF{{float64(x), float64(y)}, {float64(x + 1), float64(y)}, {float64(x + 1), float64(y + 1)}, {float64(x), float64(y + 1)}}
+
+	var above [4]bool
+	for i, v := range corners {
+		above[i] = float64(v) >= level
+	}
+
+	crossing := func(edge int) PointF {
+		a, b := edge, (edge+1)%4
+		// interpolate from the top or left corner, so that both cells sharing the edge agree exactly
+		if edge >= 2 {
+			a, b = b, a
+		}
+		t := (level - float64(corners[a])) / (float64(corners[b]) - float64(corners[a]))
+		return PointF{
+			positions[a].X + t*(positions[b].X-positions[a].X),
+			positions[a].Y + t*(positions[b].Y-positions[a].Y),
+		}
+	}
+	segment := func(e1, e2 int) contourSegment {
+		return contourSegment{[2]int{edgeIDs[e1], edgeIDs[e2]}, [2]PointF{crossing(e1), crossing(e2)}}
+	}
+
+	var crossed []int
+	for edge := 0; edge < 4; edge++ {
+		if above[edge] != above[(edge+1)%4] {
+			crossed = append(crossed, edge)
+		}
+	}
+	switch len(crossed) {
+	case 2:
+		return append(segments, segment(crossed[0], crossed[1]))
+	case 4:
+		// saddle, decided by the average of the cell
+		center := (float64(corners[0]) + float64(corners[1]) + float64(corners[2]) + float64(corners[3])) / 4
+		if (center >= level) == above[0] {
+			// top left and bottom right corners are connected, cut off the other two corners
+			return append(segments, segment(0, 1), segment(2, 3))
+		}
+		return append(segments, segment(3, 0), segment(1, 2))
+	}
+	return segments
+}
+
+// stitchSegments joins the segments of a single level into polylines.
+// Closed lines end with their first point.
+func stitchSegments(segments []contourSegment) [][]PointF {
+	byEdge := make(map[int][]int, len(segments)*2)
+	for i, s := range segments {
+		byEdge[s.edges[0]] = append(byEdge[s.edges[0]], i)
+		byEdge[s.edges[1]] = append(byEdge[s.edges[1]], i)
+	}
+
+	used := make([]bool, len(segments))
+	var lines [][]PointF
+	follow := func(start, end int) {
+		// start at the given end of the segment and walk along its other end
+		used[start] = true
+		s := segments[start]
+		line := []PointF{s.points[end], s.points[1-end]}
+		edge := s.edges[1-end]
+		for {
+			next := -1
+			for _, candidate := range byEdge[edge] {
+				if !used[candidate] {
+					next = candidate
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			used[next] = true
+			s := segments[next]
+			if s.edges[0] == edge {
+				line = append(line, s.points[1])
+				edge = s.edges[1]
+			} else {
+				line = append(line, s.points[0])
+				edge = s.edges[0]
+			}
+		}
+		if edge == segments[start].edges[end] {
+			line[len(line)-1] = line[0]
+		}
+		lines = append(lines, line)
+	}
+
+	// open lines start at an edge touched by a single segment
+	for i, s := range segments {
+		if used[i] {
+			continue
+		}
+		for end, edge := range s.edges {
+			if len(byEdge[edge]) == 1 {
+				follow(i, end)
+				break
+			}
+		}
+	}
+	// the remaining segments form closed rings
+	for i := range segments {
+		if !used[i] {
+			follow(i, 0)
+		}
+	}
+	return lines
+}
+
+// chaikin smoothes the line by cutting each corner at a quarter of the adjacent segments.
+// Open lines keep their end points.
+func chaikin(points []PointF, closed bool) []PointF {
+	if len(points) < 3 {
+		return points
+	}
+	cut := func(a, b PointF) (PointF, PointF) {
+		return PointF{0.75*a.X + 0.25*b.X, 0.75*a.Y + 0.25*b.Y}, PointF{0.25*a.X + 0.75*b.X, 0.25*a.Y + 0.75*b.Y}
+	}
+
+	smoothed := make([]PointF, 0, len(points)*2)
+	if closed {
+		for i := 0; i+1 < len(points); i++ {
+			q, r := cut(points[i], points[i+1])
+			smoothed = append(smoothed, q, r)
+		}
+		return append(smoothed, smoothed[0])
+	}
+
+	smoothed = append(smoothed, points[0])
+	for i := 0; i+1 < len(points); i++ {
+		q, r := cut(points[i], points[i+1])
+		if i > 0 {
+			smoothed = append(smoothed, q)
+		}
+		if i+2 < len(points) {
+			smoothed = append(smoothed, r)
+		}
+	}
+	return append(smoothed, points[len(points)-1])
+}
+
+// WriteContoursGeoJSON writes the contour lines as a GeoJSON FeatureCollection of LineStrings
+// in WGS84 longitude/latitude, with the properties elevation and index.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) WriteContoursGeoJSON(w io.Writer, contours []Contour) error {
+	features := make([]geoJSONFeature, len(contours))
+	for i, c := range contours {
+		coordinates, err := srtmImg.lonLatCoordinates(c.Points)
+		if err != nil {
+			return err
+		}
+		features[i] = geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: map[string]interface{}{"elevation": c.Elevation, "index": c.Index},
+		}
+	}
+	return writeGeoJSON(w, features)
+}
+
+// WriteContoursSVG writes the contour lines as SVG paths in sample coordinates,
+// so that the drawing overlays the images of the tile. Index contours are drawn thicker.
+func (srtmImg *SRTMImage) WriteContoursSVG(w io.Writer, contours []Contour) error {
+	width, height := srtmImg.Width(), srtmImg.Height()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintln(bw, `<g fill="none" stroke="#8b5a2b" stroke-linejoin="round" transform="translate(0.5 0.5)">`)
+	for _, c := range contours {
+		width := 0.5
+		class := "contour"
+		if c.Index {
+			width = 1.5
+			class = "contour index"
+		}
+		fmt.Fprintf(bw, `<path class="%s" data-elevation="%g" stroke-width="%g" d="`, class, c.Elevation, width)
+		for i, p := range c.Points {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(bw, "%s%.2f %.2f", command, p.X, p.Y)
+		}
+		fmt.Fprintln(bw, `"/>`)
+	}
+	fmt.Fprintln(bw, "</g>")
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
@@ -0,0 +1,126 @@
+package srtm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// coneImage returns a tile with a cone of the given height in its center, falling by one meter per sample.
+func coneImage(height int) *SRTMImage {
+	return newTestImage(Tile{48, 12}, func(x, y int) int16 {
+		d := math.Hypot(float64(x-600), float64(y-600))
+		return int16(math.Max(0, float64(height)-d))
+	})
+}
+
+func TestContoursCone(t *testing.T) {
+	img := coneImage(100)
+	contours, err := img.Contours(ContourOptions{Interval: 20, IndexInterval: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// levels 20, 40, 60, 80 and 100 at the very top, each a single closed ring
+	if len(contours) != 5 {
+		t.Fatal("Contours should return 5 lines, but returned", len(contours))
+	}
+	for i, c := range contours[:4] {
+		if c.Elevation != float64(20*(i+1)) || !c.Closed {
+			t.Error("contour should be a closed ring at", 20*(i+1), "but was", c.Elevation, c.Closed)
+		}
+		if c.Index != (i%2 == 1) {
+			t.Error("index contours should be every 40 meters, but", c.Elevation, "was", c.Index)
+		}
+		radius := 100 - c.Elevation
+		for _, p := range c.Points {
+			if d := math.Hypot(p.X-600, p.Y-600); math.Abs(d-radius) > 1 {
+				t.Fatal("contour point should lie at radius", radius, "but was at", d)
+			}
+		}
+	}
+
+	// index contours are multiples of IndexInterval, not counted from Base
+	contours, err = img.Contours(ContourOptions{Interval: 20, Base: 10, IndexInterval: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range contours {
+		if c.Index != (c.Elevation == 30 || c.Elevation == 90) {
+			t.Error("only the contours at 30 and 90 meters should be index contours, but", c.Elevation, "was", c.Index)
+		}
+	}
+}
+
+func TestContoursOpenAndVoids(t *testing.T) {
+	// a ramp crosses the tile from north to south, so its contours are open lines
+	img := newTestImage(Tile{}, func(x, y int) int16 { return int16(x) })
+	contours, err := img.Contours(ContourOptions{Interval: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contours) != 2 || contours[0].Closed || len(contours[0].Points) != 1201 {
+		t.Fatal("Contours should return 2 open lines across the tile, but returned", len(contours))
+	}
+
+	// a void in the middle of the line splits it
+	img.Data[600*1201+500] = VoidValue
+	contours, _ = img.Contours(ContourOptions{Interval: 500})
+	if len(contours) != 3 {
+		t.Error("a void should split the contour line, but Contours returned", len(contours))
+	}
+
+	smoothed, _ := img.Contours(ContourOptions{Interval: 500, Smoothing: 1})
+	first, last := smoothed[0].Points[0], smoothed[0].Points[len(smoothed[0].Points)-1]
+	if first != contours[0].Points[0] || last != contours[0].Points[len(contours[0].Points)-1] {
+		t.Error("smoothing should keep the end points of open lines")
+	}
+
+	_, err = img.Contours(ContourOptions{})
+	if errors.Is(err, ErrInvalidContourInterval) == false {
+		t.Error("Contours without interval should return an ErrInvalidContourInterval error, but returned", err)
+	}
+}
+
+func TestWriteContours(t *testing.T) {
+	img := coneImage(30)
+	contours, _ := img.Contours(ContourOptions{Interval: 20, IndexInterval: 100})
+
+	var buf bytes.Buffer
+	if err := img.WriteContoursGeoJSON(&buf, contours); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates [][2]float64
+			}
+			Properties struct {
+				Elevation float64
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Features) != 1 || collection.Features[0].Geometry.Type != "LineString" || collection.Features[0].Properties.Elevation != 20 {
+		t.Fatal("GeoJSON should contain a single LineString at 20 meters, but was", buf.String())
+	}
+	for _, c := range collection.Features[0].Geometry.Coordinates {
+		// the ring has a radius of 10 samples around the tile center
+		if math.Abs(c[0]-12.5) > 11.0/1200 || math.Abs(c[1]-48.5) > 11.0/1200 {
+			t.Fatal("GeoJSON coordinates should be longitude/latitude around the tile center, but were", c)
+		}
+	}
+
+	buf.Reset()
+	if err := img.WriteContoursSVG(&buf, contours); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<svg") || strings.Count(buf.String(), "<path") != 1 {
+		t.Error("SVG should contain a single path, but was", buf.String())
+	}
+}
@@ -0,0 +1,300 @@
+package srtm
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+var ErrTileNotFound = errors.New("SRTM tile not found in dataset")
+var ErrOcean = errors.New("SRTM tile is ocean")
+
+// Dataset provides elevation data for a region covered by several SRTM tiles.
+// Tiles are indexed by their file names and only read from disk on first access.
+// Read tiles are kept in a TileCache, which by default is unlimited.
+// A Dataset is safe for concurrent use.
+type Dataset struct {
+	// IsOcean reports whether a tile missing from the dataset is known to be ocean.
+	// SRTM does not provide tiles which are ocean only, so without this function
+	// every query for a missing tile results in ErrTileNotFound.
+	IsOcean func(Tile) bool
+
+	files map[Tile]datasetFile
+	cache *TileCache
+}
+
+type datasetFile struct {
+	path   string
+	entry  string // name of the zip archive entry, if any
+	format SRTMFormat
+}
+
+// OpenDataset indexes all SRTM1 and SRTM3 tiles in dir and its subdirectories.
+// Besides plain .hgt files, gzip compressed .hgt.gz files and all tiles inside of zip archives are indexed.
+// Files whose name is not a valid tile name or whose size does not match any format are ignored.
+// If a tile is found several times, the file with the highest resolution is used.
+func OpenDataset(dir string) (*Dataset, error) {
+	d := &Dataset{files: make(map[Tile]datasetFile)}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch compressionOf(path) {
+		case zipCompressed:
+			return d.indexZip(path)
+		case gzipCompressed:
+			if !isHGTName(path) {
+				return nil
+			}
+			tile, err := ParseTile(path)
+			if err != nil {
+				return nil
+			}
+			format, err := gzipFormat(path)
+			if err != nil {
+				return nil
+			}
+			d.addFile(tile, datasetFile{path: path, format: format})
+		default:
+			if !isHGTName(path) {
+				return nil
+			}
+			tile, err := ParseTile(path)
+			if err != nil {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			format, err := FormatFromSize(info.Size())
+			if err != nil {
+				return nil
+			}
+			d.addFile(tile, datasetFile{path: path, format: format})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.cache = NewTileCache(0, d.loadTile)
+	return d, nil
+}
+
+// indexZip adds all tiles inside the zip archive to the index.
+// Archives which cannot be read are ignored.
+func (d *Dataset) indexZip(name string) error {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	files := zipTileFiles(&r.Reader)
+	for _, f := range files {
+		tile, err := ParseTile(path.Base(f.Name))
+		if err != nil && len(files) == 1 {
+			tile, err = ParseTile(name)
+		}
+		if err != nil {
+			continue
+		}
+		format, err := FormatFromZipFile(f)
+		if err != nil {
+			continue
+		}
+		d.addFile(tile, datasetFile{path: name, entry: f.Name, format: format})
+	}
+	return nil
+}
+
+// addFile adds the file to the index, unless the tile is already indexed with at least the same resolution.
+func (d *Dataset) addFile(tile Tile, file datasetFile) {
+	if existing, ok := d.files[tile]; ok && existing.format.Size() >= file.format.Size() {
+		return
+	}
+	d.files[tile] = file
+}
+
+// Cache returns the cache holding the tiles read by the dataset,
+// e.g. to limit its memory usage or to export its counters.
+func (d *Dataset) Cache() *TileCache {
+	return d.cache
+}
+
+// Tiles returns all tiles of the dataset, sorted from south to north and west to east.
+func (d *Dataset) Tiles() []Tile {
+	tiles := make([]Tile, 0, len(d.files))
+	for tile := range d.files {
+		tiles = append(tiles, tile)
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].Lat != tiles[j].Lat {
+			return tiles[i].Lat < tiles[j].Lat
+		}
+		return tiles[i].Lon < tiles[j].Lon
+	})
+	return tiles
+}
+
+// HasTile returns true if the dataset contains a file for the given tile.
+func (d *Dataset) HasTile(tile Tile) bool {
+	_, ok := d.files[tile]
+	return ok
+}
+
+// Image returns the elevation data of the given tile, reading it on first access.
+// Missing tiles result in ErrOcean if IsOcean reports so, otherwise in ErrTileNotFound.
+func (d *Dataset) Image(tile Tile) (*SRTMImage, error) {
+	if !d.HasTile(tile) {
+		if d.IsOcean != nil && d.IsOcean(tile) {
+			return nil, fmt.Errorf("%w: %v", ErrOcean, tile)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrTileNotFound, tile)
+	}
+	return d.cache.Get(tile)
+}
+
+// loadTile reads the file of the tile from disk.
+func (d *Dataset) loadTile(tile Tile) (*SRTMImage, error) {
+	file := d.files[tile]
+
+	var img *SRTMImage
+	var err error
+	switch {
+	case file.entry != "":
+		img, err = readZipEntry(file.path, file.entry)
+	case compressionOf(file.path) == gzipCompressed:
+		img, err = openGzipImage(file.path)
+	default:
+		img, err = readHGTFile(file.path, file.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	img.setTile(tile)
+	return img, nil
+}
+
+func readHGTFile(name string, format SRTMFormat) (*SRTMImage, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decoder{Name: name, Strict: true}.Decode(f, format)
+}
+
+func readZipEntry(name, entry string) (*SRTMImage, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	files := zipTileFiles(&r.Reader)
+	for _, f := range files {
+		if f.Name == entry {
+			return readZipFile(f, name, len(files) == 1)
+		}
+	}
+	return nil, fmt.Errorf("%w: %v, entry: %v", ErrNoTileInArchive, name, entry)
+}
+
+// ElevationAt returns the elevation at the given WGS84 latitude/longitude.
+// Interpolation near the edge of a tile seamlessly uses the samples of the
+// neighbouring tile, if the dataset contains it in the same format.
+// Queries on ocean tiles return an elevation of 0 alongside ErrOcean.
+func (d *Dataset) ElevationAt(lat, lon float64, interpolation Interpolation) (float64, error) {
+	if math.IsNaN(lat) || math.IsNaN(lon) || math.IsInf(lon, 0) || lat < -90 || lat > 90 {
+		return 0, fmt.Errorf("%w: lat: %v, lon: %v", ErrLatLonOutOfBounds, lat, lon)
+	}
+	lon = normalizeLon(lon)
+
+	img, err := d.Image(TileAt(lat, lon))
+	if err != nil {
+		return 0, err
+	}
+	x, y := img.LatLonToPoint(lat, lon)
+	return interpolate(d.sampler(img), x, y, interpolation)
+}
+
+// normalizeLon wraps a longitude in degrees into the range -180 to 180.
+func normalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// terrainElevation returns the elevation like ElevationAt, with ocean tiles at sea level instead of ErrOcean.
+func (d *Dataset) terrainElevation(lat, lon float64, interpolation Interpolation) (float64, error) {
+	v, err := d.ElevationAt(lat, lon, interpolation)
+	if errors.Is(err, ErrOcean) {
+		return 0, nil
+	}
+	return v, err
+}
+
+// formatAt returns the format of the tile at lat/lon, SRTM3 for ocean tiles.
+func (d *Dataset) formatAt(lat, lon float64) (SRTMFormat, error) {
+	img, err := d.Image(TileAt(lat, lon))
+	if errors.Is(err, ErrOcean) {
+		return SRTM3Format, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return img.Format, nil
+}
+
+// sampler returns a sample function for interpolation, which continues into
+// the neighbouring tiles for coordinates outside of img.
+func (d *Dataset) sampler(img *SRTMImage) func(x, y int) int16 {
+	last := img.Format.Size() - 1
+	return func(x, y int) int16 {
+		if x >= 0 && x <= last && y >= 0 && y <= last {
+			return img.Data[y*(last+1)+x]
+		}
+
+		// the edge rows and columns are shared, so the neighbour's first
+		// row or column equals our last one and vice versa
+		tile := img.Tile
+		nx, ny := x, y
+		if x < 0 {
+			tile.Lon--
+			nx += last
+		} else if x > last {
+			tile.Lon++
+			nx -= last
+		}
+		if y < 0 {
+			tile.Lat++
+			ny += last
+		} else if y > last {
+			tile.Lat--
+			ny -= last
+		}
+		tile.Lon = wrapLon(tile.Lon)
+
+		if tile.IsValid() && d.HasTile(tile) {
+			neighbour, err := d.Image(tile)
+			if err == nil && neighbour.Format == img.Format {
+				return neighbour.clampedAt(nx, ny)
+			}
+		}
+		return img.clampedAt(x, y)
+	}
+}
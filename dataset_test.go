@@ -0,0 +1,79 @@
+package srtm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestTile writes img as a .hgt file named after its tile into dir.
+func writeTestTile(t *testing.T, dir string, img *SRTMImage) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, img.Tile.Filename()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := binary.Write(f, SRTMByteOrder, img.Data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDatasetElevationAt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// west tile rises to 1200 at its eastern edge, the east tile continues from there
+	writeTestTile(t, dir, newTestImage(Tile{48, 11}, func(x, y int) int16 { return int16(x) }))
+	writeTestTile(t, filepath.Join(dir, "sub"), newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(1200 + x) }))
+
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiles := dataset.Tiles(); len(tiles) != 2 || tiles[0] != (Tile{48, 11}) || tiles[1] != (Tile{48, 12}) {
+		t.Fatal("Tiles() should return N48E011 and N48E012, but returned", tiles)
+	}
+
+	v, err := dataset.ElevationAt(48.5, 12, Bilinear)
+	if err != nil || v != 1200 {
+		t.Error("ElevationAt on the shared edge should return 1200, but returned", v, err)
+	}
+	// just west of the edge, bicubic needs samples of both tiles
+	v, err = dataset.ElevationAt(48.5, 12-0.5/1200, Bicubic)
+	if err != nil || math.Abs(v-1199.5) > 1e-6 {
+		t.Error("ElevationAt across the tile boundary should return 1199.5, but returned", v, err)
+	}
+	v, err = dataset.ElevationAt(48.5, 12+0.5/1200, Bicubic)
+	if err != nil || math.Abs(v-1200.5) > 1e-6 {
+		t.Error("ElevationAt across the tile boundary should return 1200.5, but returned", v, err)
+	}
+}
+
+func TestDatasetMissingTile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 { return 0 }))
+
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dataset.ElevationAt(10.5, 10.5, Bilinear)
+	if errors.Is(err, ErrTileNotFound) == false {
+		t.Error("ElevationAt on a missing tile should return ErrTileNotFound, but returned", err)
+	}
+
+	dataset.IsOcean = func(tile Tile) bool { return tile.Lat < 20 }
+	v, err := dataset.ElevationAt(10.5, 10.5, Bilinear)
+	if errors.Is(err, ErrOcean) == false || v != 0 {
+		t.Error("ElevationAt on an ocean tile should return 0 and ErrOcean, but returned", v, err)
+	}
+	_, err = dataset.ElevationAt(30.5, 10.5, Bilinear)
+	if errors.Is(err, ErrTileNotFound) == false {
+		t.Error("ElevationAt on a missing tile should return ErrTileNotFound, but returned", err)
+	}
+}
@@ -0,0 +1,155 @@
+package srtm
+
+import (
+	"math"
+	"math/big"
+)
+
+type point2 struct {
+	x, y float64
+}
+
+type triangle struct {
+	vertices [3]int // counterclockwise
+	// degenerate triangles never contain other points in their circumcircle
+	degenerate bool
+}
+
+func newTriangle(points []point2, a, b, c int) triangle {
+	orientation := orient(points[a], points[b], points[c])
+	if orientation < 0 {
+		b, c = c, b
+	}
+	return triangle{vertices: [3]int{a, b, c}, degenerate: orientation == 0}
+}
+
+// orient returns a positive value if the triangle a, b, c is counterclockwise, a negative value
+// if it is clockwise and 0 if the points are collinear. Like inCircle, the sign is exact.
+func orient(a, b, c point2) float64 {
+	left, right := (b.x-a.x)*(c.y-a.y), (b.y-a.y)*(c.x-a.x)
+	det := left - right
+	if math.Abs(det) > 1e-12*(math.Abs(left)+math.Abs(right)) {
+		return det
+	}
+	const prec = 2048
+	value := func(v float64) *big.Float { return new(big.Float).SetPrec(prec).SetFloat64(v) }
+	sub := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Sub(x, y) }
+	mul := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Mul(x, y) }
+	exact := sub(
+		mul(sub(value(b.x), value(a.x)), sub(value(c.y), value(a.y))),
+		mul(sub(value(b.y), value(a.y)), sub(value(c.x), value(a.x))))
+	return float64(exact.Sign())
+}
+
+// inCircumcircle reports whether p lies strictly inside of the circumcircle of the triangle.
+// Points on the circle are outside, so that cocircular points, as on the grid of a hole
+// boundary, consistently result in one of their valid triangulations.
+func (t triangle) inCircumcircle(points []point2, p point2) bool {
+	if t.degenerate {
+		return false
+	}
+	return inCircle(points[t.vertices[0]], points[t.vertices[1]], points[t.vertices[2]], p) > 0
+}
+
+// inCircle returns a positive value if d lies inside of the circumcircle of the counterclockwise
+// triangle a, b, c, a negative value if it lies outside and 0 if it lies on the circle.
+// The sign is exact: if the float64 determinant is too close to 0 to be trusted,
+// it is evaluated again with exact arithmetic.
+func inCircle(a, b, c, d point2) float64 {
+	adx, ady := a.x-d.x, a.y-d.y
+	bdx, bdy := b.x-d.x, b.y-d.y
+	cdx, cdy := c.x-d.x, c.y-d.y
+	aLift, bLift, cLift := adx*adx+ady*ady, bdx*bdx+bdy*bdy, cdx*cdx+cdy*cdy
+	det := aLift*(bdx*cdy-cdx*bdy) + bLift*(cdx*ady-adx*cdy) + cLift*(adx*bdy-bdx*ady)
+	// the magnitude of the terms bounds the rounding error of the determinant
+	permanent := aLift*(math.Abs(bdx*cdy)+math.Abs(cdx*bdy)) +
+		bLift*(math.Abs(cdx*ady)+math.Abs(adx*cdy)) +
+		cLift*(math.Abs(adx*bdy)+math.Abs(bdx*ady))
+	if math.Abs(det) > 1e-12*permanent {
+		return det
+	}
+	return exactInCircle(a, b, c, d)
+}
+
+// exactInCircle evaluates the determinant of inCircle without rounding.
+func exactInCircle(a, b, c, d point2) float64 {
+	// products and sums of float64 values fit into this precision without rounding
+	const prec = 2048
+	value := func(v float64) *big.Float { return new(big.Float).SetPrec(prec).SetFloat64(v) }
+	sub := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Sub(x, y) }
+	add := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Add(x, y) }
+	mul := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(prec).Mul(x, y) }
+
+	adx, ady := sub(value(a.x), value(d.x)), sub(value(a.y), value(d.y))
+	bdx, bdy := sub(value(b.x), value(d.x)), sub(value(b.y), value(d.y))
+	cdx, cdy := sub(value(c.x), value(d.x)), sub(value(c.y), value(d.y))
+	aLift := add(mul(adx, adx), mul(ady, ady))
+	bLift := add(mul(bdx, bdx), mul(bdy, bdy))
+	cLift := add(mul(cdx, cdx), mul(cdy, cdy))
+	det := add(add(
+		mul(aLift, sub(mul(bdx, cdy), mul(cdx, bdy))),
+		mul(bLift, sub(mul(cdx, ady), mul(adx, cdy)))),
+		mul(cLift, sub(mul(adx, bdy), mul(bdx, ady))))
+	return float64(det.Sign())
+}
+
+// delaunay returns the Delaunay triangulation of the points as triples of indices into points,
+// using the Bowyer-Watson algorithm. Collinear points result in no triangles at all.
+func delaunay(points []point2) [][3]int {
+	if len(points) < 3 {
+		return nil
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+	span := math.Max(maxX-minX, maxY-minY) + 1
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+
+	// the super triangle encloses all points, its vertices are appended to the points
+	n := len(points)
+	all := make([]point2, n, n+3)
+	copy(all, points)
+	all = append(all,
+		point2{midX - 20*span, midY - span},
+		point2{midX, midY + 20*span},
+		point2{midX + 20*span, midY - span})
+	triangles := []triangle{newTriangle(all, n, n+1, n+2)}
+
+	for i := 0; i < n; i++ {
+		p := all[i]
+		edges := make(map[[2]int]int)
+		kept := triangles[:0]
+		for _, t := range triangles {
+			if !t.inCircumcircle(all, p) {
+				kept = append(kept, t)
+				continue
+			}
+			for j := 0; j < 3; j++ {
+				a, b := t.vertices[j], t.vertices[(j+1)%3]
+				if a > b {
+					a, b = b, a
+				}
+				edges[[2]int{a, b}]++
+			}
+		}
+		triangles = kept
+		// edges of only one removed triangle form the boundary of the cavity
+		for edge, count := range edges {
+			if count == 1 {
+				triangles = append(triangles, newTriangle(all, edge[0], edge[1], i))
+			}
+		}
+	}
+
+	var result [][3]int
+	for _, t := range triangles {
+		if t.vertices[0] < n && t.vertices[1] < n && t.vertices[2] < n {
+			result = append(result, t.vertices)
+		}
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package srtm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidDTED  = errors.New("invalid DTED file")
+	ErrDTEDChecksum = errors.New("DTED checksum mismatch")
+)
+
+const (
+	// dtedUHLLength, dtedDSILength and dtedACCLength are the lengths of the
+	// user header label, data set identification and accuracy description records.
+	dtedUHLLength = 80
+	dtedDSILength = 648
+	dtedACCLength = 2700
+	// dtedSentinel starts every data record.
+	dtedSentinel = 0xaa
+	// dtedVoidValue marks missing elevations, -32767 in signed magnitude.
+	dtedVoidValue = -32767
+)
+
+// dtedHeader holds the fields of the DTED headers needed to read the data records.
+type dtedHeader struct {
+	// lat and lon are the origin at the south west corner in degrees,
+	// latInterval and lonInterval the spacing of the samples in tenths of arc seconds
+	lat, lon                 float64
+	latInterval, lonInterval int
+	// lonLines is the number of data records, latPoints the number of samples per record
+	lonLines, latPoints int
+}
+
+// DecodeDTED reads a Digital Terrain Elevation Data file of level 0, 1 or 2 (.dt0, .dt1, .dt2),
+// the format SRTM was originally delivered in. The UHL, DSI and ACC headers are followed by a
+// data record per longitude line from west to east, holding signed magnitude samples from south
+// to north and a checksum, which is verified.
+//
+// Like DecodeGeoTIFF, the elevations are resampled onto the SRTM grid of the tile, which is exact
+// for level 1 and 2 below 50° latitude, where the longitude spacing matches the latitude spacing.
+// Level 0 is resampled onto the SRTM3 grid, voids are kept.
+func DecodeDTED(r io.Reader) (*SRTMImage, error) {
+	br := bufio.NewReader(r)
+	headers := make([]byte, dtedUHLLength+dtedDSILength+dtedACCLength)
+	if _, err := io.ReadFull(br, headers); err != nil {
+		return nil, fmt.Errorf("%w: headers: %v", ErrInvalidDTED, err)
+	}
+	header, err := parseDTEDHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &raster{
+		width:  header.lonLines,
+		height: header.latPoints,
+		west:   header.lon,
+		scaleX: float64(header.lonInterval) / 36000,
+		scaleY: float64(header.latInterval) / 36000,
+	}
+	g.north = header.lat + float64(g.height-1)*g.scaleY
+	g.data = make([]int16, g.width*g.height)
+
+	record := make([]byte, 8+2*header.latPoints+4)
+	for x := 0; x < header.lonLines; x++ {
+		if _, err := io.ReadFull(br, record); err != nil {
+			return nil, fmt.Errorf("%w: data record %v of %v: %v", ErrInvalidDTED, x+1, header.lonLines, err)
+		}
+		if record[0] != dtedSentinel {
+			return nil, fmt.Errorf("%w: data record %v starts with %#x instead of %#x", ErrInvalidDTED, x+1, record[0], dtedSentinel)
+		}
+		if line := int(binary.BigEndian.Uint16(record[4:])); line != x {
+			return nil, fmt.Errorf("%w: data record %v holds longitude line %v", ErrInvalidDTED, x+1, line)
+		}
+
+		var sum uint32
+		for _, b := range record[:len(record)-4] {
+			sum += uint32(b)
+		}
+		if expected := binary.BigEndian.Uint32(record[len(record)-4:]); sum != expected {
+			return nil, fmt.Errorf("%w: data record %v at longitude %.5f sums to %v, but its checksum is %v", ErrDTEDChecksum, x+1, g.west+float64(x)*g.scaleX, sum, expected)
+		}
+
+		// the samples run from south to north
+		for i := 0; i < header.latPoints; i++ {
+			v := binary.BigEndian.Uint16(record[8+2*i:])
+			elevation := int(v & 0x7fff)
+			if v&0x8000 != 0 {
+				elevation = -elevation
+			}
+			g.data[(g.height-1-i)*g.width+x] = rasterElevation(float64(elevation), dtedVoidValue)
+		}
+	}
+	return g.resample(ErrInvalidDTED)
+}
+
+// parseDTEDHeaders validates the UHL, DSI and ACC headers and returns the fields of the user header label.
+func parseDTEDHeaders(headers []byte) (*dtedHeader, error) {
+	uhl := string(headers[:dtedUHLLength])
+	dsi := string(headers[dtedUHLLength : dtedUHLLength+dtedDSILength])
+	acc := string(headers[dtedUHLLength+dtedDSILength:])
+	if !strings.HasPrefix(uhl, "UHL") {
+		return nil, fmt.Errorf("%w: missing user header label, starts with %q", ErrInvalidDTED, uhl[:3])
+	}
+	if !strings.HasPrefix(dsi, "DSI") {
+		return nil, fmt.Errorf("%w: missing data set identification, starts with %q", ErrInvalidDTED, dsi[:3])
+	}
+	if !strings.HasPrefix(acc, "ACC") {
+		return nil, fmt.Errorf("%w: missing accuracy description, starts with %q", ErrInvalidDTED, acc[:3])
+	}
+
+	// the product level designator, e.g. DTED1
+	if level := dsi[59:64]; !strings.HasPrefix(level, "DTED") || level[4] < '0' || level[4] > '2' {
+		return nil, fmt.Errorf("%w: product level %q", ErrInvalidDTED, level)
+	}
+
+	header := &dtedHeader{}
+
+	var err error
+	if header.lon, err = parseDTEDAngle(uhl[4:12]); err != nil {
+		return nil, fmt.Errorf("%w: longitude of origin: %v", ErrInvalidDTED, err)
+	}
+	if header.lat, err = parseDTEDAngle(uhl[12:20]); err != nil {
+		return nil, fmt.Errorf("%w: latitude of origin: %v", ErrInvalidDTED, err)
+	}
+	fields := []struct {
+		name  string
+		value string
+		field *int
+	}{
+		{"longitude interval", uhl[20:24], &header.lonInterval},
+		{"latitude interval", uhl[24:28], &header.latInterval},
+		{"number of longitude lines", uhl[47:51], &header.lonLines},
+		{"number of latitude points", uhl[51:55], &header.latPoints},
+	}
+	for _, f := range fields {
+		v, err := strconv.Atoi(strings.TrimSpace(f.value))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("%w: %v %q", ErrInvalidDTED, f.name, f.value)
+		}
+		*f.field = v
+	}
+	if header.lonLines < 2 || header.latPoints < 2 {
+		return nil, fmt.Errorf("%w: %vx%v samples", ErrInvalidDTED, header.lonLines, header.latPoints)
+	}
+	if header.lat < -90 || header.lat > 90 || header.lon < -180 || header.lon > 180 {
+		return nil, fmt.Errorf("%w: origin %v/%v", ErrInvalidDTED, header.lat, header.lon)
+	}
+	return header, nil
+}
+
+// parseDTEDAngle parses an angle of the form DDDMMSSH, e.g. 0120000E, into degrees.
+func parseDTEDAngle(s string) (float64, error) {
+	if len(s) != 8 {
+		return 0, fmt.Errorf("invalid angle %q", s)
+	}
+	degrees, errDegrees := strconv.Atoi(s[:3])
+	minutes, errMinutes := strconv.Atoi(s[3:5])
+	seconds, errSeconds := strconv.Atoi(s[5:7])
+	if errDegrees != nil || errMinutes != nil || errSeconds != nil || minutes >= 60 || seconds >= 60 {
+		return 0, fmt.Errorf("invalid angle %q", s)
+	}
+	angle := float64(degrees) + float64(minutes)/60 + float64(seconds)/3600
+	switch s[7] {
+	case 'N', 'E':
+		return angle, nil
+	case 'S', 'W':
+		return -angle, nil
+	}
+	return 0, fmt.Errorf("invalid hemisphere in angle %q", s)
+}
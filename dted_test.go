@@ -0,0 +1,122 @@
+package srtm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encodeTestDTED returns a DTED file of the given level with the origin lat/lon and intervals in tenths of arc seconds.
+// The elevation of the samples is given by column x from west to east and row y from south to north.
+func encodeTestDTED(level, lat, lon, lonInterval, latInterval int, elevation func(x, y int) int) []byte {
+	angle := func(v int, positive, negative byte) string {
+		h := positive
+		if v < 0 {
+			v, h = -v, negative
+		}
+		return fmt.Sprintf("%03d0000%c", v, h)
+	}
+	lonLines, latPoints := 1+36000/lonInterval, 1+36000/latInterval
+
+	uhl := fmt.Sprintf("UHL1%s%s%04d%04d0016U  %-12s%04d%04d0%24s", angle(lon, 'E', 'W'), angle(lat, 'N', 'S'), lonInterval, latInterval, "", lonLines, latPoints, "")
+	dsi := fmt.Sprintf("DSIU%55sDTED%d", "", level)
+	dsi += strings.Repeat(" ", dtedDSILength-len(dsi))
+	acc := "ACC" + strings.Repeat(" ", dtedACCLength-3)
+	data := []byte(uhl + dsi + acc)
+
+	for x := 0; x < lonLines; x++ {
+		record := []byte{dtedSentinel, 0, byte(x >> 8), byte(x), 0, 0, 0, 0}
+		binary.BigEndian.PutUint16(record[4:], uint16(x))
+		for y := 0; y < latPoints; y++ {
+			e := elevation(x, y)
+			v := uint16(e)
+			if e < 0 {
+				// signed magnitude
+				v = uint16(-e) | 0x8000
+			}
+			record = append(record, byte(v>>8), byte(v))
+		}
+		var sum uint32
+		for _, b := range record {
+			sum += uint32(b)
+		}
+		record = append(record, byte(sum>>24), byte(sum>>16), byte(sum>>8), byte(sum))
+		data = append(data, record...)
+	}
+	return data
+}
+
+func TestDecodeDTED(t *testing.T) {
+	data := encodeTestDTED(1, -34, -71, 30, 30, func(x, y int) int {
+		if x == 7 && y == 5 {
+			return dtedVoidValue
+		}
+		return x - y
+	})
+	name := filepath.Join(t.TempDir(), "s34.dt1")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img, err := OpenSRTMImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := newTestImage(Tile{-34, -71}, func(x, y int) int16 { return int16(x - (1200 - y)) })
+	expected.Data[(1200-5)*1201+7] = VoidValue
+	if !equalImages(expected, img) || img.IsMosaic() {
+		t.Error("DTED level 1 should decode to the SRTM3 tile S34W071, but returned", img.Format, img.Tile)
+	}
+}
+
+func TestDecodeDTEDLongitudeInterval(t *testing.T) {
+	// level 2 between 50° and 70° latitude has half as many longitude lines
+	data := encodeTestDTED(2, 60, 10, 20, 10, func(x, y int) int { return 2 * x })
+	img, err := DecodeDTED(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM1Format || img.Tile != (Tile{60, 10}) {
+		t.Fatal("DTED level 2 should decode to the SRTM1 tile N60E010, but returned", img.Format, img.Tile)
+	}
+	for _, x := range []int{0, 1, 2, 3, 3600} {
+		if e := img.Data[1800*3601+x]; int(e) != x {
+			t.Error("elevation at column", x, "should be", x, "but was", e)
+		}
+	}
+}
+
+func TestDecodeDTEDErrors(t *testing.T) {
+	valid := encodeTestDTED(0, 48, 12, 300, 300, func(x, y int) int { return -x * y })
+	img, err := DecodeDTED(strings.NewReader(string(valid)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM3Format || img.Data[1200] != -14400 {
+		t.Error("DTED level 0 should decode to SRTM3 with negative elevations, but returned", img.Format, img.Data[1200])
+	}
+
+	headers := dtedUHLLength + dtedDSILength + dtedACCLength
+	for _, c := range []struct {
+		name   string
+		modify func(data []byte) []byte
+		err    error
+	}{
+		{"corrupted sample", func(data []byte) []byte { data[headers+10]++; return data }, ErrDTEDChecksum},
+		{"corrupted checksum", func(data []byte) []byte { data[headers+8+2*121+3]++; return data }, ErrDTEDChecksum},
+		{"missing sentinel", func(data []byte) []byte { data[headers] = 0; return data }, ErrInvalidDTED},
+		{"missing UHL", func(data []byte) []byte { data[0] = 'X'; return data }, ErrInvalidDTED},
+		{"invalid level", func(data []byte) []byte { data[dtedUHLLength+63] = '7'; return data }, ErrInvalidDTED},
+		{"invalid origin", func(data []byte) []byte { data[11] = 'Q'; return data }, ErrInvalidDTED},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-1] }, ErrInvalidDTED},
+	} {
+		data := c.modify(append([]byte(nil), valid...))
+		if _, err := DecodeDTED(strings.NewReader(string(data))); !errors.Is(err, c.err) {
+			t.Errorf("%v should return a %v error, but returned %v", c.name, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package srtm
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidDataLength = errors.New("data length does not match SRTM image format")
+
+// Encode writes the elevation data as HGT data in SRTMByteOrder,
+// which can be read again by NewSRTMImage.
+func (srtmImg *SRTMImage) Encode(w io.Writer) error {
+	if err := srtmImg.checkDataLength(); err != nil {
+		return err
+	}
+	return binary.Write(w, SRTMByteOrder, srtmImg.Data)
+}
+
+// EncodeZip writes a zip archive holding the HGT data as a single entry named after the tile.
+func (srtmImg *SRTMImage) EncodeZip(w io.Writer) error {
+	if err := srtmImg.checkDataLength(); err != nil {
+		return err
+	}
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	entry, err := zw.Create(srtmImg.Tile.Filename())
+	if err != nil {
+		return err
+	}
+	if err := srtmImg.Encode(entry); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// EncodeGzip writes the gzip compressed HGT data, storing the file name of the tile in the gzip header
+// if the tile is known.
+func (srtmImg *SRTMImage) EncodeGzip(w io.Writer) error {
+	if err := srtmImg.checkDataLength(); err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(w)
+	if srtmImg.TileKnown {
+		zw.Name = srtmImg.Tile.Filename()
+	}
+	if err := srtmImg.Encode(zw); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// WriteFile writes the image to the file with the given name.
+// Like OpenSRTMImage, names ending in .zip or .gz are compressed accordingly,
+// names ending in .tif, .asc or .xyz are written as deflate compressed GeoTIFF, ESRI ASCII grid or XYZ file.
+// The data is written to a temporary file in the same directory, which replaces the named file
+// only on success, so a failing write leaves an existing file untouched.
+func (srtmImg *SRTMImage) WriteFile(name string) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if encode, ok := rasterEncoders[rasterExtension(name)]; ok {
+		err = encode(srtmImg, f)
+	} else {
+		switch compressionOf(name) {
+		case zipCompressed:
+			err = srtmImg.EncodeZip(f)
+		case gzipCompressed:
+			err = srtmImg.EncodeGzip(f)
+		default:
+			err = srtmImg.Encode(f)
+		}
+	}
+	if err == nil {
+		// CreateTemp restricts the file to its owner, os.Create would not
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("%v: %w", name, err)
+	}
+	return nil
+}
+
+// WriteTile writes the image into dir using the canonical file name of its tile,
+// e.g. N48E012.hgt or N48E012.hgt.zip if zipped. It returns the path of the written file.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) WriteTile(dir string, zipped bool) (string, error) {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, srtmImg.Tile.Filename())
+	if zipped {
+		name += ".zip"
+	}
+	return name, srtmImg.WriteFile(name)
+}
+
+// checkDataLength verifies that the data matches the dimensions of the format.
+// HGT files hold a single tile, so mosaics are rejected.
+func (srtmImg *SRTMImage) checkDataLength() error {
+	size := srtmImg.Format.Size()
+	if size < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidFormat, srtmImg.Format)
+	}
+	if srtmImg.IsMosaic() {
+		return fmt.Errorf("%w: mosaic of %vx%v tiles", ErrNotSingleTile, srtmImg.Columns, srtmImg.Rows)
+	}
+	if len(srtmImg.Data) != size*size {
+		return fmt.Errorf("%w: %v, length: %v", ErrInvalidDataLength, srtmImg.Format, len(srtmImg.Data))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func equalImages(a, b *SRTMImage) bool {
+	if a.Format != b.Format || a.Tile != b.Tile || a.TileKnown != b.TileKnown || len(a.Data) != len(b.Data) {
+		return false
+	}
+	for i := range a.Data {
+		if a.Data[i] != b.Data[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	img := newTestImage(Tile{}, func(x, y int) int16 { return int16(x*31 - y*17) })
+	img.Data[42] = VoidValue
+	// without a name the decoder cannot know the tile
+	img.TileKnown = false
+
+	var buf bytes.Buffer
+	if err := img.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 2884802 {
+		t.Error("Encode should write 2884802 bytes, but wrote", buf.Len())
+	}
+	decoded, err := NewSRTMImage(&buf, SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(img, decoded) {
+		t.Error("NewSRTMImage should decode the encoded image unchanged")
+	}
+}
+
+func TestWriteTileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	img := newTestImage(Tile{-33, -71}, func(x, y int) int16 { return int16(x + y) })
+
+	for _, zipped := range []bool{false, true} {
+		name, err := img.WriteTile(dir, zipped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "S33W071.hgt"
+		if zipped {
+			expected += ".zip"
+		}
+		if filepath.Base(name) != expected {
+			t.Error("WriteTile should write", expected, "but wrote", name)
+		}
+		decoded, err := OpenSRTMImage(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalImages(img, decoded) {
+			t.Error("OpenSRTMImage should read the written tile unchanged, zipped:", zipped)
+		}
+	}
+
+	name := filepath.Join(dir, "tile.gz")
+	if err := img.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := OpenSRTMImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(img, decoded) {
+		t.Error("OpenSRTMImage should read the gzipped tile unchanged")
+	}
+
+	for _, name := range []string{"tile.tif", "tile.ASC", "tile.xyz"} {
+		name = filepath.Join(dir, name)
+		if err := img.WriteFile(name); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := OpenSRTMImage(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalImages(img, decoded) {
+			t.Error("OpenSRTMImage should read the tile written to", filepath.Base(name), "unchanged")
+		}
+	}
+}
+
+func TestEncodeInvalidDataLength(t *testing.T) {
+	img := &SRTMImage{Data: make([]int16, 10), Format: SRTM1Format}
+	err := img.Encode(&bytes.Buffer{})
+	if errors.Is(err, ErrInvalidDataLength) == false {
+		t.Error("Encode should return an ErrInvalidDataLength error, but returned", err)
+	}
+}
+
+func TestWriteFileFailure(t *testing.T) {
+	mosaic, err := Mosaic(
+		newTestImage(Tile{48, 12}, func(x, y int) int16 { return 1 }),
+		newTestImage(Tile{48, 13}, func(x, y int) int16 { return 2 }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"N48E012.hgt", "N48E012.hgt.zip", "N48E012.hgt.gz"} {
+		path := filepath.Join(dir, name)
+		if err := mosaic.WriteFile(path); !errors.Is(err, ErrNotSingleTile) {
+			t.Error("WriteFile of a mosaic should return an ErrNotSingleTile error, but returned", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("WriteFile should not leave the file", name, "after failing, but returned", err)
+		}
+	}
+
+	// a failing write must keep an existing tile
+	tile := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x) })
+	path, err := tile.WriteTile(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := NewSRTMImage(bytes.NewReader(make([]byte, 1201*1201*2)), SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unknown.WriteFile(path + ".zip"); !errors.Is(err, ErrUnknownTile) {
+		t.Error("WriteFile of an unknown tile should return an ErrUnknownTile error, but returned", err)
+	}
+	if err := mosaic.WriteFile(path); !errors.Is(err, ErrNotSingleTile) {
+		t.Error("WriteFile of a mosaic should return an ErrNotSingleTile error, but returned", err)
+	}
+	img, err := OpenSRTMImage(path)
+	if err != nil || !equalImages(img, tile) {
+		t.Error("WriteFile should keep the existing tile after failing, but returned", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Error("WriteFile should not leave temporary files, but found", len(entries), err)
+	}
+}
+
+func TestEncodeUnknownTile(t *testing.T) {
+	img, err := NewSRTMImage(bytes.NewReader(make([]byte, 1201*1201*2)), SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.TileKnown {
+		t.Fatal("NewSRTMImage should not know the tile, but returned", img.Tile)
+	}
+	if _, err := img.WriteTile(t.TempDir(), false); !errors.Is(err, ErrUnknownTile) {
+		t.Error("WriteTile should return an ErrUnknownTile error, but returned", err)
+	}
+	encoders := map[string]func(w *bytes.Buffer) error{
+		"EncodeZip":       func(w *bytes.Buffer) error { return img.EncodeZip(w) },
+		"EncodeGeoTIFF":   func(w *bytes.Buffer) error { return img.EncodeGeoTIFF(w, GeoTIFFOptions{}) },
+		"EncodeASCIIGrid": func(w *bytes.Buffer) error { return img.EncodeASCIIGrid(w) },
+		"EncodeXYZ":       func(w *bytes.Buffer) error { return img.EncodeXYZ(w) },
+	}
+	for name, encode := range encoders {
+		if err := encode(&bytes.Buffer{}); !errors.Is(err, ErrUnknownTile) {
+			t.Error(name, "should return an ErrUnknownTile error, but returned", err)
+		}
+	}
+	if err := img.Encode(&bytes.Buffer{}); err != nil {
+		t.Error("Encode should not need the tile, but returned", err)
+	}
+
+	decoded, err := Decoder{Name: "data/N48E012.hgt"}.Decode(bytes.NewReader(make([]byte, 1201*1201*2)), SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.TileKnown || decoded.Tile != (Tile{48, 12}) {
+		t.Error("Decoder should take the tile from its name, but returned", decoded.Tile, decoded.TileKnown)
+	}
+}
@@ -0,0 +1,101 @@
+package srtm
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrUnknownFormat = errors.New("unknown SRTM format")
+
+// FormatFromSize returns the format of HGT data with the given length in bytes.
+func FormatFromSize(size int64) (SRTMFormat, error) {
+	for _, format := range []SRTMFormat{SRTM1Format, SRTM3Format} {
+		if size == int64(format.Size()*format.Size()*2) {
+			return format, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: size: %v bytes", ErrUnknownFormat, size)
+}
+
+// DetectFormat returns the format of the HGT data remaining in r.
+// The length is taken from a Len or Size method, as provided by bytes.Reader,
+// strings.Reader and io.SectionReader, or by seeking to the end of an io.Seeker.
+// Seekers are restored to their current offset.
+func DetectFormat(r io.Reader) (SRTMFormat, error) {
+	size, err := readerLen(r)
+	if err != nil {
+		return -1, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	return FormatFromSize(size)
+}
+
+// FormatFromZipFile returns the format of a HGT file inside a zip archive
+// from the uncompressed size recorded in the archive.
+func FormatFromZipFile(f *zip.File) (SRTMFormat, error) {
+	format, err := FormatFromSize(int64(f.UncompressedSize64))
+	if err != nil {
+		return -1, fmt.Errorf("%v: %w", f.Name, err)
+	}
+	return format, nil
+}
+
+// readerLen returns the number of bytes remaining in r.
+// Seekers, like io.SectionReader, are asked for their position before their size,
+// as Size reports the total size regardless of what has been read already.
+func readerLen(r io.Reader) (int64, error) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), nil
+	case io.Seeker:
+		current, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1, err
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1, err
+		}
+		_, err = r.Seek(current, io.SeekStart)
+		return end - current, err
+	case interface{ Size() int64 }:
+		return r.Size(), nil
+	}
+	return -1, errors.New("length of reader cannot be determined")
+}
+
+// OpenSRTMImage reads the HGT file with the given name, detecting its format from the file size.
+// If the file name is a valid tile name as accepted by ParseTile, the tile of the image is set accordingly.
+//
+// Zip (.zip) and gzip (.gz) compressed files are decompressed transparently.
+// Zip archives holding several tiles must be named after one of them,
+// use OpenArchive to read all tiles instead.
+//
+// GeoTIFFs (.tif, .tiff), ESRI ASCII grids (.asc), XYZ files (.xyz) and DTED files (.dt0, .dt1, .dt2)
+// are read with DecodeGeoTIFF, DecodeASCIIGrid, DecodeXYZ and DecodeDTED, taking the tile from their georeferencing.
+func OpenSRTMImage(name string) (*SRTMImage, error) {
+	if decode, ok := rasterDecoders[rasterExtension(name)]; ok {
+		return openRaster(name, decode)
+	}
+	switch compressionOf(name) {
+	case zipCompressed:
+		return openZipImage(name)
+	case gzipCompressed:
+		return openGzipImage(name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	format, err := DetectFormat(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	// the decoder takes the tile from the name
+	return Decoder{Name: name, Strict: true}.Decode(f, format)
+}
@@ -0,0 +1,106 @@
+package srtm
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromSize(t *testing.T) {
+	format, err := FormatFromSize(25934402)
+	if err != nil || format != SRTM1Format {
+		t.Error("FormatFromSize(25934402) should return SRTM1, but returned", format, err)
+	}
+	format, err = FormatFromSize(2884802)
+	if err != nil || format != SRTM3Format {
+		t.Error("FormatFromSize(2884802) should return SRTM3, but returned", format, err)
+	}
+	_, err = FormatFromSize(2884800)
+	if errors.Is(err, ErrUnknownFormat) == false {
+		t.Error("FormatFromSize(2884800) should return an ErrUnknownFormat error, but returned", err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	data := make([]byte, 2884802)
+	format, err := DetectFormat(bytes.NewReader(data))
+	if err != nil || format != SRTM3Format {
+		t.Error("DetectFormat(bytes.Reader) should return SRTM3, but returned", format, err)
+	}
+	format, err = DetectFormat(io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))))
+	if err != nil || format != SRTM3Format {
+		t.Error("DetectFormat(io.SectionReader) should return SRTM3, but returned", format, err)
+	}
+	// a partially read SectionReader only has the remaining bytes
+	section := io.NewSectionReader(bytes.NewReader(make([]byte, 2884802+2)), 0, 2884802+2)
+	section.Read(make([]byte, 2))
+	format, err = DetectFormat(section)
+	if err != nil || format != SRTM3Format {
+		t.Error("DetectFormat(partially read io.SectionReader) should return SRTM3, but returned", format, err)
+	}
+	_, err = DetectFormat(io.MultiReader(bytes.NewReader(data)))
+	if errors.Is(err, ErrUnknownFormat) == false {
+		t.Error("DetectFormat(io.MultiReader) should return an ErrUnknownFormat error, but returned", err)
+	}
+	_, err = DetectFormat(strings.NewReader("too short"))
+	if errors.Is(err, ErrUnknownFormat) == false {
+		t.Error("DetectFormat(strings.Reader) should return an ErrUnknownFormat error, but returned", err)
+	}
+}
+
+func TestDetectFormatSeeker(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "N48E012.hgt")
+	if err := os.WriteFile(name, make([]byte, 2884802+2), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// skip two bytes, so that the remaining data matches the format
+	f.Seek(2, io.SeekStart)
+	format, err := DetectFormat(f)
+	if err != nil || format != SRTM3Format {
+		t.Error("DetectFormat(os.File) should return SRTM3, but returned", format, err)
+	}
+	if offset, _ := f.Seek(0, io.SeekCurrent); offset != 2 {
+		t.Error("DetectFormat should restore the offset, but offset was", offset)
+	}
+}
+
+func TestFormatFromZipFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	entry, _ := w.Create("N48E012.hgt")
+	entry.Write(make([]byte, 2884802))
+	w.Close()
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	format, err := FormatFromZipFile(r.File[0])
+	if err != nil || format != SRTM3Format {
+		t.Error("FormatFromZipFile should return SRTM3, but returned", format, err)
+	}
+}
+
+func TestOpenSRTMImage(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{-33, -71}, func(x, y int) int16 { return int16(y) }))
+
+	img, err := OpenSRTMImage(filepath.Join(dir, "S33W071.hgt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM3Format || img.Tile != (Tile{-33, -71}) || img.Data[1201] != 1 {
+		t.Error("OpenSRTMImage returned wrong image", img.Format, img.Tile)
+	}
+}
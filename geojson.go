@@ -0,0 +1,57 @@
+package srtm
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// PointF is a point in fractional sample coordinates of an image,
+// with integer values at the sample centers as returned by LatLonToPoint.
+type PointF struct {
+	X, Y float64
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// writeGeoJSON writes the features as a GeoJSON FeatureCollection.
+func writeGeoJSON(w io.Writer, features []geoJSONFeature) error {
+	if features == nil {
+		features = []geoJSONFeature{}
+	}
+	return json.NewEncoder(w).Encode(geoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+}
+
+// lonLatCoordinates converts sample coordinates into GeoJSON positions,
+// which are longitude first and rounded to 7 decimals, about a centimeter.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) lonLatCoordinates(points []PointF) ([][2]float64, error) {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return nil, err
+	}
+	coordinates := make([][2]float64, len(points))
+	for i, p := range points {
+		lat, lon := srtmImg.FractionalPointToLatLon(p.X, p.Y)
+		coordinates[i] = [2]float64{roundDecimals(lon, 7), roundDecimals(lat, 7)}
+	}
+	return coordinates, nil
+}
+
+func roundDecimals(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
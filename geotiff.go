@@ -0,0 +1,295 @@
+package srtm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+var ErrInvalidTileSize = errors.New("GeoTIFF tile size must be a positive multiple of 16")
+
+// GeoTIFFOptions configure EncodeGeoTIFF.
+type GeoTIFFOptions struct {
+	// Deflate compresses the data with deflate and the horizontal differencing predictor,
+	// which typically halves the size of elevation data.
+	Deflate bool
+	// TileSize organizes the data in square tiles of the given width and height instead of strips,
+	// which speeds up reading parts of large mosaics. It must be a multiple of 16, 0 writes strips.
+	TileSize int
+}
+
+// TIFF tags, field types and values used in GeoTIFFs.
+const (
+	tagImageWidth                = 256
+	tagImageLength               = 257
+	tagBitsPerSample             = 258
+	tagCompression               = 259
+	tagPhotometricInterpretation = 262
+	tagStripOffsets              = 273
+	tagSamplesPerPixel           = 277
+	tagRowsPerStrip              = 278
+	tagStripByteCounts           = 279
+	tagPlanarConfiguration       = 284
+	tagPredictor                 = 317
+	tagTileWidth                 = 322
+	tagTileLength                = 323
+	tagTileOffsets               = 324
+	tagTileByteCounts            = 325
+	tagSampleFormat              = 339
+	tagModelPixelScale           = 33550
+	tagModelTiepoint             = 33922
+	tagModelTransformation       = 34264
+	tagGeoKeyDirectory           = 34735
+	tagGDALNoData                = 42113
+
+	tiffASCII  = 2
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+
+	compressionNone    = 1
+	compressionLZW     = 5
+	compressionDeflate = 8
+	// compressionAdobeDeflate is the deflate code of older writers.
+	compressionAdobeDeflate = 32946
+
+	predictorNone          = 1
+	predictorHorizontal    = 2
+	predictorFloatingPoint = 3
+
+	sampleFormatUint  = 1
+	sampleFormatInt   = 2
+	sampleFormatFloat = 3
+
+	// GeoKeys of the GeoKeyDirectory
+	geoKeyModelType        = 1024
+	geoKeyRasterType       = 1025
+	geoKeyGeographicType   = 2048
+	geoKeyGeogAngularUnits = 2054
+
+	modelTypeGeographic = 2
+	rasterPixelIsPoint  = 2
+	epsgWGS84           = 4326
+	angularUnitDegree   = 9102
+)
+
+// tiffField is an entry of a TIFF image file directory.
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte // values in the byte order of the file
+}
+
+func shortField(tag uint16, values ...uint16) tiffField {
+	data := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(data[2*i:], v)
+	}
+	return tiffField{tag, tiffShort, uint32(len(values)), data}
+}
+
+func longField(tag uint16, values ...uint32) tiffField {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return tiffField{tag, tiffLong, uint32(len(values)), data}
+}
+
+func doubleField(tag uint16, values ...float64) tiffField {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return tiffField{tag, tiffDouble, uint32(len(values)), data}
+}
+
+func asciiField(tag uint16, value string) tiffField {
+	data := append([]byte(value), 0)
+	return tiffField{tag, tiffASCII, uint32(len(data)), data}
+}
+
+// EncodeGeoTIFF writes the elevation data as a GeoTIFF of signed 16 bit samples, which GIS software
+// like GDAL and QGIS read with correct elevations and location. The image is georeferenced
+// in WGS84 (EPSG:4326) with the pixel-is-point convention of SRTM, as the samples lie on the
+// whole degrees. Voids are flagged by the GDAL_NODATA value -32768. Mosaics up to MaxMosaicSamples
+// are supported, images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) EncodeGeoTIFF(w io.Writer, options GeoTIFFOptions) error {
+	width, height := srtmImg.Width(), srtmImg.Height()
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidFormat, srtmImg.Format)
+	}
+	// larger mosaics could not be read again
+	if err := checkMosaicSize(srtmImg.Format, atLeastOne(srtmImg.Columns), atLeastOne(srtmImg.Rows)); err != nil {
+		return err
+	}
+	if len(srtmImg.Data) != width*height {
+		return fmt.Errorf("%w: %v, length: %v", ErrInvalidDataLength, srtmImg.Format, len(srtmImg.Data))
+	}
+	if options.TileSize < 0 || options.TileSize%16 != 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidTileSize, options.TileSize)
+	}
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+
+	// the data is split into chunks, which are strips of rows or tiles
+	chunkWidth, chunkHeight := width, (8192+width-1)/width
+	if options.TileSize > 0 {
+		chunkWidth, chunkHeight = options.TileSize, options.TileSize
+	}
+	across, down := (width+chunkWidth-1)/chunkWidth, (height+chunkHeight-1)/chunkHeight
+
+	var chunks [][]byte
+	for cy := 0; cy < down; cy++ {
+		for cx := 0; cx < across; cx++ {
+			chunk, err := srtmImg.geoTIFFChunk(cx*chunkWidth, cy*chunkHeight, chunkWidth, chunkHeight, options)
+			if err != nil {
+				return err
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	offsets := make([]uint32, len(chunks))
+	byteCounts := make([]uint32, len(chunks))
+	offset := uint64(8)
+	for i, chunk := range chunks {
+		offsets[i], byteCounts[i] = uint32(offset), uint32(len(chunk))
+		offset += uint64(len(chunk))
+		// words start on even offsets
+		offset += offset % 2
+	}
+
+	compression, predictor := uint16(compressionNone), uint16(predictorNone)
+	if options.Deflate {
+		compression, predictor = compressionDeflate, predictorHorizontal
+	}
+	spacing := 1 / float64(srtmImg.Format.Size()-1)
+	north, west := srtmImg.FractionalPointToLatLon(0, 0)
+	fields := []tiffField{
+		longField(tagImageWidth, uint32(width)),
+		longField(tagImageLength, uint32(height)),
+		shortField(tagBitsPerSample, 16),
+		shortField(tagCompression, compression),
+		shortField(tagPhotometricInterpretation, 1), // black is zero
+		shortField(tagSamplesPerPixel, 1),
+		shortField(tagPlanarConfiguration, 1),
+		shortField(tagPredictor, predictor),
+		shortField(tagSampleFormat, sampleFormatInt),
+		doubleField(tagModelPixelScale, spacing, spacing, 0),
+		doubleField(tagModelTiepoint, 0, 0, 0, west, north, 0),
+		shortField(tagGeoKeyDirectory,
+			1, 1, 0, 4, // version 1.1.0 with 4 keys
+			geoKeyModelType, 0, 1, modelTypeGeographic,
+			geoKeyRasterType, 0, 1, rasterPixelIsPoint,
+			geoKeyGeographicType, 0, 1, epsgWGS84,
+			geoKeyGeogAngularUnits, 0, 1, angularUnitDegree,
+		),
+		asciiField(tagGDALNoData, fmt.Sprint(VoidValue)),
+	}
+	if options.TileSize > 0 {
+		fields = append(fields,
+			longField(tagTileWidth, uint32(chunkWidth)),
+			longField(tagTileLength, uint32(chunkHeight)),
+			longField(tagTileOffsets, offsets...),
+			longField(tagTileByteCounts, byteCounts...),
+		)
+	} else {
+		fields = append(fields,
+			longField(tagStripOffsets, offsets...),
+			longField(tagRowsPerStrip, uint32(chunkHeight)),
+			longField(tagStripByteCounts, byteCounts...),
+		)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].tag < fields[j].tag })
+
+	// the directory follows the data, values larger than four bytes follow the directory
+	ifdOffset := offset
+	valueOffset := ifdOffset + 2 + uint64(len(fields))*12 + 4
+	var ifd, values bytes.Buffer
+	binary.Write(&ifd, binary.LittleEndian, uint16(len(fields)))
+	for _, f := range fields {
+		binary.Write(&ifd, binary.LittleEndian, f.tag)
+		binary.Write(&ifd, binary.LittleEndian, f.typ)
+		binary.Write(&ifd, binary.LittleEndian, f.count)
+		if len(f.data) <= 4 {
+			var inline [4]byte
+			copy(inline[:], f.data)
+			ifd.Write(inline[:])
+			continue
+		}
+		binary.Write(&ifd, binary.LittleEndian, uint32(valueOffset+uint64(values.Len())))
+		values.Write(f.data)
+		if values.Len()%2 == 1 {
+			values.WriteByte(0)
+		}
+	}
+	binary.Write(&ifd, binary.LittleEndian, uint32(0)) // no further directory
+	if valueOffset+uint64(values.Len()) > math.MaxUint32 {
+		return fmt.Errorf("GeoTIFF exceeds 4 GB, size: %v bytes", valueOffset+uint64(values.Len()))
+	}
+
+	header := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(header[4:], uint32(ifdOffset))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if len(chunk)%2 == 1 {
+			chunk = append(chunk, 0)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	if _, err := w.Write(ifd.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(values.Bytes())
+	return err
+}
+
+// geoTIFFChunk encodes the samples of the rectangle as strip or tile of the GeoTIFF.
+// Strips end at the last row, tiles are padded with voids.
+func (srtmImg *SRTMImage) geoTIFFChunk(x0, y0, chunkWidth, chunkHeight int, options GeoTIFFOptions) ([]byte, error) {
+	width, height := srtmImg.Width(), srtmImg.Height()
+	if options.TileSize == 0 && y0+chunkHeight > height {
+		chunkHeight = height - y0
+	}
+	raw := make([]byte, chunkWidth*chunkHeight*2)
+	for y := 0; y < chunkHeight; y++ {
+		previous := uint16(0)
+		for x := 0; x < chunkWidth; x++ {
+			v := VoidValue
+			if x0+x < width && y0+y < height {
+				v = srtmImg.Data[(y0+y)*width+x0+x]
+			}
+			sample := uint16(v)
+			if options.Deflate {
+				// horizontal differencing wraps around like the unsigned samples of libtiff
+				sample, previous = sample-previous, sample
+			}
+			binary.LittleEndian.PutUint16(raw[(y*chunkWidth+x)*2:], sample)
+		}
+	}
+	if !options.Deflate {
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
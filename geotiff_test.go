@@ -0,0 +1,147 @@
+package srtm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+// testTIFF holds the fields of the first directory of a little endian TIFF,
+// with integer values widened to uint32 and doubles as float64.
+type testTIFF struct {
+	data    []byte
+	ints    map[uint16][]uint32
+	doubles map[uint16][]float64
+	ascii   map[uint16]string
+}
+
+func parseTestTIFF(t *testing.T, data []byte) *testTIFF {
+	t.Helper()
+	if string(data[:4]) != "II*\x00" {
+		t.Fatal("TIFF should start with a little endian header, but was", data[:4])
+	}
+	tiff := &testTIFF{data, map[uint16][]uint32{}, map[uint16][]float64{}, map[uint16]string{}}
+	le := binary.LittleEndian
+	ifd := le.Uint32(data[4:])
+	n := int(le.Uint16(data[ifd:]))
+	for i := 0; i < n; i++ {
+		entry := data[int(ifd)+2+i*12:]
+		tag, typ, count := le.Uint16(entry), le.Uint16(entry[2:]), int(le.Uint32(entry[4:]))
+		size := map[uint16]int{tiffASCII: 1, tiffShort: 2, tiffLong: 4, tiffDouble: 8}[typ] * count
+		values := entry[8:12]
+		if size > 4 {
+			values = data[le.Uint32(entry[8:]):]
+		}
+		for k := 0; k < count; k++ {
+			switch typ {
+			case tiffShort:
+				tiff.ints[tag] = append(tiff.ints[tag], uint32(le.Uint16(values[2*k:])))
+			case tiffLong:
+				tiff.ints[tag] = append(tiff.ints[tag], le.Uint32(values[4*k:]))
+			case tiffDouble:
+				tiff.doubles[tag] = append(tiff.doubles[tag], math.Float64frombits(le.Uint64(values[8*k:])))
+			}
+		}
+		if typ == tiffASCII {
+			tiff.ascii[tag] = string(values[:count-1])
+		}
+	}
+	return tiff
+}
+
+// samples decodes the chunk at the given offset and byte count into samples, undoing the predictor.
+func (tiff *testTIFF) samples(t *testing.T, offset, count uint32, rowLength int) []int16 {
+	t.Helper()
+	raw := tiff.data[offset : offset+count]
+	if tiff.ints[tagCompression][0] == compressionDeflate {
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raw, err = io.ReadAll(zr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples := make([]int16, len(raw)/2)
+	for i := range samples {
+		v := binary.LittleEndian.Uint16(raw[2*i:])
+		if tiff.ints[tagPredictor][0] == predictorHorizontal && i%rowLength > 0 {
+			v += uint16(samples[i-1])
+		}
+		samples[i] = int16(v)
+	}
+	return samples
+}
+
+func TestEncodeGeoTIFF(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x - y) })
+	img.Data[1201*5+7] = VoidValue
+
+	for _, options := range []GeoTIFFOptions{{}, {Deflate: true}, {TileSize: 256}, {Deflate: true, TileSize: 512}} {
+		var buf bytes.Buffer
+		if err := img.EncodeGeoTIFF(&buf, options); err != nil {
+			t.Fatal(err)
+		}
+		tiff := parseTestTIFF(t, buf.Bytes())
+		if tiff.ints[tagImageWidth][0] != 1201 || tiff.ints[tagImageLength][0] != 1201 || tiff.ints[tagSampleFormat][0] != sampleFormatInt || tiff.ints[tagBitsPerSample][0] != 16 {
+			t.Error("GeoTIFF should hold 1201x1201 signed 16 bit samples, but had", tiff.ints)
+		}
+		if scale := tiff.doubles[tagModelPixelScale]; scale[0] != 1.0/1200 || scale[1] != 1.0/1200 {
+			t.Error("pixel scale should be 1/1200 degree, but was", scale)
+		}
+		if tiepoint := tiff.doubles[tagModelTiepoint]; tiepoint[0] != 0 || tiepoint[1] != 0 || tiepoint[3] != 12 || tiepoint[4] != 49 {
+			t.Error("tiepoint should put the first sample at 49/12, but was", tiepoint)
+		}
+		keys := tiff.ints[tagGeoKeyDirectory]
+		expected := map[uint32]uint32{geoKeyModelType: modelTypeGeographic, geoKeyRasterType: rasterPixelIsPoint, geoKeyGeographicType: epsgWGS84}
+		for i := 4; i+3 < len(keys); i += 4 {
+			if v, ok := expected[keys[i]]; ok && keys[i+3] == v {
+				delete(expected, keys[i])
+			}
+		}
+		if keys[3] != uint32(len(keys)/4-1) || len(expected) > 0 {
+			t.Error("GeoKeyDirectory should declare EPSG:4326 with pixel-is-point, but was", keys)
+		}
+		if tiff.ascii[tagGDALNoData] != "-32768" {
+			t.Error("GDAL_NODATA should be -32768, but was", tiff.ascii[tagGDALNoData])
+		}
+
+		// reassemble the image from its chunks
+		data := make([]int16, 1201*1201)
+		if options.TileSize > 0 {
+			size := int(tiff.ints[tagTileWidth][0])
+			across := (1201 + size - 1) / size
+			for i, offset := range tiff.ints[tagTileOffsets] {
+				samples := tiff.samples(t, offset, tiff.ints[tagTileByteCounts][i], size)
+				for k, v := range samples {
+					x, y := i%across*size+k%size, i/across*size+k/size
+					if x < 1201 && y < 1201 {
+						data[y*1201+x] = v
+					} else if v != VoidValue {
+						t.Fatal("tiles should be padded with voids, but were", v)
+					}
+				}
+			}
+		} else {
+			var samples []int16
+			for i, offset := range tiff.ints[tagStripOffsets] {
+				samples = append(samples, tiff.samples(t, offset, tiff.ints[tagStripByteCounts][i], 1201)...)
+			}
+			copy(data, samples)
+			if len(samples) != len(data) {
+				t.Error("strips should hold", len(data), "samples, but held", len(samples))
+			}
+		}
+		if !equalImages(img, &SRTMImage{Data: data, Format: img.Format, Tile: img.Tile, TileKnown: true}) {
+			t.Error("GeoTIFF should hold the elevation data with options", options)
+		}
+	}
+
+	if err := img.EncodeGeoTIFF(io.Discard, GeoTIFFOptions{TileSize: 100}); !errors.Is(err, ErrInvalidTileSize) {
+		t.Error("a tile size of 100 should return an ErrInvalidTileSize error, but returned", err)
+	}
+}
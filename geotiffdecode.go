@@ -0,0 +1,321 @@
+package srtm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/tiff/lzw"
+)
+
+var ErrUnsupportedGeoTIFF = errors.New("unsupported GeoTIFF")
+
+// tiffDirectory holds the fields of a TIFF image file directory.
+type tiffDirectory struct {
+	order   binary.ByteOrder
+	ints    map[uint16][]uint64
+	doubles map[uint16][]float64
+	ascii   map[uint16]string
+}
+
+func (d *tiffDirectory) int(tag uint16, defaultValue uint64) uint64 {
+	if v, ok := d.ints[tag]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return defaultValue
+}
+
+// DecodeGeoTIFF reads a single band GeoTIFF of int16 or float32 elevations in WGS84 (EPSG:4326),
+// as provided by Copernicus GLO-30, NASADEM or ALOS AW3D30. Strip and tile layouts
+// are supported, uncompressed or compressed with deflate or LZW.
+//
+// The elevations are resampled bilinearly onto the SRTM grid of the tiles the GeoTIFF covers,
+// SRTM1 for resolutions of up to 1.5 arc seconds and SRTM3 otherwise, so that the image can be used
+// like any other. GeoTIFFs on the SRTM grid, like the ones written by EncodeGeoTIFF, are read exactly.
+// Samples outside of the GeoTIFF and its GDAL_NODATA value are voids, float elevations are rounded.
+func DecodeGeoTIFF(r io.Reader) (*SRTMImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	g, err := decodeGeoTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	return g.resample(ErrUnsupportedGeoTIFF)
+}
+
+func decodeGeoTIFF(data []byte) (*raster, error) {
+	dir, err := readTIFFDirectory(data)
+	if err != nil {
+		return nil, err
+	}
+	g := &raster{width: int(dir.int(tagImageWidth, 0)), height: int(dir.int(tagImageLength, 0))}
+	if g.width <= 0 || g.height <= 0 {
+		return nil, fmt.Errorf("%w: image size %vx%v", ErrUnsupportedGeoTIFF, g.width, g.height)
+	}
+	if err := checkRasterSize(g.width, g.height, ErrUnsupportedGeoTIFF); err != nil {
+		return nil, err
+	}
+	if err := dir.georeference(g); err != nil {
+		return nil, err
+	}
+	if err := dir.readSamples(data, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// readTIFFDirectory parses the header and the first image file directory of a classic TIFF.
+func readTIFFDirectory(data []byte) (*tiffDirectory, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("%w: truncated header", ErrUnsupportedGeoTIFF)
+	}
+	dir := &tiffDirectory{ints: map[uint16][]uint64{}, doubles: map[uint16][]float64{}, ascii: map[uint16]string{}}
+	switch string(data[:2]) {
+	case "II":
+		dir.order = binary.LittleEndian
+	case "MM":
+		dir.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: not a TIFF file", ErrUnsupportedGeoTIFF)
+	}
+	if version := dir.order.Uint16(data[2:]); version != 42 {
+		return nil, fmt.Errorf("%w: TIFF version %v, BigTIFF is not supported", ErrUnsupportedGeoTIFF, version)
+	}
+
+	offset := uint64(dir.order.Uint32(data[4:]))
+	if offset+2 > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: truncated directory offset %v", ErrUnsupportedGeoTIFF, offset)
+	}
+	n := uint64(dir.order.Uint16(data[offset:]))
+	if offset+2+n*12 > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: truncated directory of %v entries", ErrUnsupportedGeoTIFF, n)
+	}
+	for i := uint64(0); i < n; i++ {
+		entry := data[offset+2+i*12:]
+		tag, typ, count := dir.order.Uint16(entry), dir.order.Uint16(entry[2:]), uint64(dir.order.Uint32(entry[4:]))
+		sizes := map[uint16]uint64{1: 1, 2: 1, 3: 2, 4: 4, 6: 1, 8: 2, 9: 4, 11: 4, 12: 8, 16: 8}
+		size, ok := sizes[typ]
+		if !ok {
+			// unknown types may be skipped
+			continue
+		}
+		values := entry[8:12]
+		if size*count > 4 {
+			valueOffset := uint64(dir.order.Uint32(entry[8:]))
+			if valueOffset+size*count > uint64(len(data)) {
+				return nil, fmt.Errorf("%w: truncated values of tag %v", ErrUnsupportedGeoTIFF, tag)
+			}
+			values = data[valueOffset : valueOffset+size*count]
+		}
+		for k := uint64(0); k < count; k++ {
+			v := values[k*size:]
+			switch typ {
+			case 1, 6: // BYTE, SBYTE
+				dir.ints[tag] = append(dir.ints[tag], uint64(v[0]))
+			case tiffShort, 8: // SHORT, SSHORT
+				dir.ints[tag] = append(dir.ints[tag], uint64(dir.order.Uint16(v)))
+			case tiffLong, 9: // LONG, SLONG
+				dir.ints[tag] = append(dir.ints[tag], uint64(dir.order.Uint32(v)))
+			case 16: // LONG8
+				dir.ints[tag] = append(dir.ints[tag], dir.order.Uint64(v))
+			case 11: // FLOAT
+				dir.doubles[tag] = append(dir.doubles[tag], float64(math.Float32frombits(dir.order.Uint32(v))))
+			case tiffDouble:
+				dir.doubles[tag] = append(dir.doubles[tag], math.Float64frombits(dir.order.Uint64(v)))
+			}
+		}
+		if typ == tiffASCII {
+			dir.ascii[tag] = strings.TrimRight(string(values[:count]), "\x00")
+		}
+	}
+	return dir, nil
+}
+
+// georeference sets the location of the pixels from the GeoTIFF tags.
+func (dir *tiffDirectory) georeference(g *raster) error {
+	keys := dir.ints[tagGeoKeyDirectory]
+	geoKeys := map[uint64]uint64{}
+	for i := 4; i+3 < len(keys); i += 4 {
+		// only keys with their value stored inline are of interest
+		if keys[i+1] == 0 {
+			geoKeys[keys[i]] = keys[i+3]
+		}
+	}
+	if modelType, ok := geoKeys[geoKeyModelType]; !ok || modelType != modelTypeGeographic {
+		return fmt.Errorf("%w: not in geographic coordinates, model type %v", ErrUnsupportedGeoTIFF, geoKeys[geoKeyModelType])
+	}
+	if crs, ok := geoKeys[geoKeyGeographicType]; ok && crs != epsgWGS84 {
+		return fmt.Errorf("%w: EPSG:%v instead of EPSG:4326", ErrUnsupportedGeoTIFF, crs)
+	}
+
+	var tiepointX, tiepointY, lon, lat float64
+	if scale, tiepoint := dir.doubles[tagModelPixelScale], dir.doubles[tagModelTiepoint]; len(scale) >= 2 && len(tiepoint) >= 6 {
+		g.scaleX, g.scaleY = scale[0], scale[1]
+		tiepointX, tiepointY, lon, lat = tiepoint[0], tiepoint[1], tiepoint[3], tiepoint[4]
+	} else if m := dir.doubles[tagModelTransformation]; len(m) == 16 {
+		if m[1] != 0 || m[4] != 0 {
+			return fmt.Errorf("%w: rotated raster", ErrUnsupportedGeoTIFF)
+		}
+		g.scaleX, g.scaleY, lon, lat = m[0], -m[5], m[3], m[7]
+	} else {
+		return fmt.Errorf("%w: missing georeferencing", ErrUnsupportedGeoTIFF)
+	}
+	if !(g.scaleX > 0) || !(g.scaleY > 0) {
+		return fmt.Errorf("%w: pixel scale %v x %v", ErrUnsupportedGeoTIFF, g.scaleX, g.scaleY)
+	}
+
+	// by default the tiepoint refers to the outer corner of the pixel, not its center
+	if geoKeys[geoKeyRasterType] != rasterPixelIsPoint {
+		tiepointX -= 0.5
+		tiepointY -= 0.5
+	}
+	g.west = lon - tiepointX*g.scaleX
+	g.north = lat + tiepointY*g.scaleY
+	return nil
+}
+
+// readSamples decodes the strips or tiles of the image into g.
+func (dir *tiffDirectory) readSamples(data []byte, g *raster) error {
+	if samples := dir.int(tagSamplesPerPixel, 1); samples != 1 {
+		return fmt.Errorf("%w: %v samples per pixel", ErrUnsupportedGeoTIFF, samples)
+	}
+	bits, format := dir.int(tagBitsPerSample, 1), dir.int(tagSampleFormat, 1)
+	var convert func(raw []byte) float64
+	switch {
+	case bits == 16 && format == sampleFormatInt:
+		convert = func(raw []byte) float64 { return float64(int16(dir.order.Uint16(raw))) }
+	case bits == 16 && format == sampleFormatUint:
+		convert = func(raw []byte) float64 { return float64(dir.order.Uint16(raw)) }
+	case bits == 32 && format == sampleFormatFloat:
+		convert = func(raw []byte) float64 { return float64(math.Float32frombits(dir.order.Uint32(raw))) }
+	default:
+		return fmt.Errorf("%w: %v bit samples of format %v", ErrUnsupportedGeoTIFF, bits, format)
+	}
+	bytesPerSample := int(bits / 8)
+
+	noData := math.NaN()
+	if s, ok := dir.ascii[tagGDALNoData]; ok {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			noData = v
+		}
+	}
+
+	chunkWidth, chunkHeight := g.width, int(dir.int(tagRowsPerStrip, uint64(g.height)))
+	offsets, byteCounts := dir.ints[tagStripOffsets], dir.ints[tagStripByteCounts]
+	if _, tiled := dir.ints[tagTileWidth]; tiled {
+		chunkWidth, chunkHeight = int(dir.int(tagTileWidth, 0)), int(dir.int(tagTileLength, 0))
+		offsets, byteCounts = dir.ints[tagTileOffsets], dir.ints[tagTileByteCounts]
+	}
+	if chunkWidth <= 0 || chunkHeight <= 0 {
+		return fmt.Errorf("%w: chunk size %vx%v", ErrUnsupportedGeoTIFF, chunkWidth, chunkHeight)
+	}
+	if chunkHeight > g.height {
+		chunkHeight = g.height
+	}
+	if err := checkRasterSize(chunkWidth, chunkHeight, ErrUnsupportedGeoTIFF); err != nil {
+		return err
+	}
+	chunkBytes := chunkWidth * chunkHeight * bytesPerSample
+	across, down := (g.width+chunkWidth-1)/chunkWidth, (g.height+chunkHeight-1)/chunkHeight
+	if len(offsets) < across*down || len(byteCounts) < len(offsets) {
+		return fmt.Errorf("%w: %v chunks instead of %v", ErrUnsupportedGeoTIFF, len(offsets), across*down)
+	}
+
+	g.data = make([]int16, g.width*g.height)
+	for c := 0; c < across*down; c++ {
+		if offsets[c]+byteCounts[c] > uint64(len(data)) {
+			return fmt.Errorf("%w: truncated chunk %v", ErrUnsupportedGeoTIFF, c)
+		}
+		raw, err := dir.decompress(data[offsets[c]:offsets[c]+byteCounts[c]], chunkBytes)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %v: %v", ErrUnsupportedGeoTIFF, c, err)
+		}
+		// strips at the bottom may be shorter
+		rows := len(raw) / (chunkWidth * bytesPerSample)
+		if rows > chunkHeight {
+			rows = chunkHeight
+		}
+		if err := dir.unpredict(raw[:rows*chunkWidth*bytesPerSample], chunkWidth*bytesPerSample, bytesPerSample); err != nil {
+			return err
+		}
+
+		x0, y0 := c%across*chunkWidth, c/across*chunkHeight
+		for y := 0; y < rows && y0+y < g.height; y++ {
+			for x := 0; x < chunkWidth && x0+x < g.width; x++ {
+				v := convert(raw[(y*chunkWidth+x)*bytesPerSample:])
+				g.data[(y0+y)*g.width+x0+x] = rasterElevation(v, noData)
+			}
+		}
+	}
+	return nil
+}
+
+// decompress returns the raw bytes of a strip or tile, at most size bytes,
+// so that a small compressed chunk cannot inflate without limit.
+func (dir *tiffDirectory) decompress(chunk []byte, size int) ([]byte, error) {
+	switch compression := dir.int(tagCompression, compressionNone); compression {
+	case compressionNone:
+		return chunk, nil
+	case compressionDeflate, compressionAdobeDeflate:
+		zr, err := zlib.NewReader(bytes.NewReader(chunk))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(io.LimitReader(zr, int64(size)))
+	case compressionLZW:
+		lr := lzw.NewReader(bytes.NewReader(chunk), lzw.MSB, 8)
+		defer lr.Close()
+		return io.ReadAll(io.LimitReader(lr, int64(size)))
+	default:
+		return nil, fmt.Errorf("compression %v", compression)
+	}
+}
+
+// unpredict reverses the predictor of the rows of raw bytes in place.
+func (dir *tiffDirectory) unpredict(raw []byte, rowLength, bytesPerSample int) error {
+	switch predictor := dir.int(tagPredictor, predictorNone); predictor {
+	case predictorNone:
+	case predictorHorizontal:
+		for row := 0; row+rowLength <= len(raw); row += rowLength {
+			for i := row + bytesPerSample; i < row+rowLength; i += bytesPerSample {
+				if bytesPerSample == 2 {
+					dir.order.PutUint16(raw[i:], dir.order.Uint16(raw[i:])+dir.order.Uint16(raw[i-2:]))
+				} else {
+					dir.order.PutUint32(raw[i:], dir.order.Uint32(raw[i:])+dir.order.Uint32(raw[i-4:]))
+				}
+			}
+		}
+	case predictorFloatingPoint:
+		// the bytes of a row are differenced, after being split into planes from the most significant byte on
+		samples := rowLength / bytesPerSample
+		planes := make([]byte, rowLength)
+		for row := 0; row+rowLength <= len(raw); row += rowLength {
+			copy(planes, raw[row:row+rowLength])
+			for i := 1; i < rowLength; i++ {
+				planes[i] += planes[i-1]
+			}
+			for i := 0; i < samples; i++ {
+				for b := 0; b < bytesPerSample; b++ {
+					v := planes[b*samples+i]
+					if dir.order == binary.LittleEndian {
+						raw[row+i*bytesPerSample+bytesPerSample-1-b] = v
+					} else {
+						raw[row+i*bytesPerSample+b] = v
+					}
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("%w: predictor %v", ErrUnsupportedGeoTIFF, predictor)
+	}
+	return nil
+}
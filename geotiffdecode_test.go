@@ -0,0 +1,212 @@
+package srtm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// lzwLiterals encodes data as TIFF LZW using only 9 bit literal codes,
+// clearing the table before it would grow to 10 bit codes.
+func lzwLiterals(data []byte) []byte {
+	const clear, eoi = 256, 257
+	var out []byte
+	var bits uint32
+	var n uint
+	put := func(code uint32) {
+		bits = bits<<9 | code
+		n += 9
+		for n >= 8 {
+			out = append(out, byte(bits>>(n-8)))
+			n -= 8
+		}
+	}
+	for i, b := range data {
+		if i%250 == 0 {
+			put(clear)
+		}
+		put(uint32(b))
+	}
+	put(eoi)
+	if n > 0 {
+		out = append(out, byte(bits<<(8-n)))
+	}
+	return out
+}
+
+// floatTestTIFF writes a big endian, LZW compressed GeoTIFF of float32 samples in a single strip,
+// with pixel-is-area georeferencing putting the corner of the first pixel at north/west.
+func floatTestTIFF(samples []float32, width, height int, west, north, scale float64) []byte {
+	be := binary.BigEndian
+	raw := make([]byte, 4*len(samples))
+	for i, v := range samples {
+		be.PutUint32(raw[4*i:], math.Float32bits(v))
+	}
+	compressed := lzwLiterals(raw)
+
+	type entry struct {
+		tag, typ uint16
+		values   []byte
+	}
+	short := func(v ...uint16) []byte {
+		b := make([]byte, 2*len(v))
+		for i, s := range v {
+			be.PutUint16(b[2*i:], s)
+		}
+		return b
+	}
+	long := func(v uint32) []byte {
+		b := make([]byte, 4)
+		be.PutUint32(b, v)
+		return b
+	}
+	double := func(v ...float64) []byte {
+		b := make([]byte, 8*len(v))
+		for i, d := range v {
+			be.PutUint64(b[8*i:], math.Float64bits(d))
+		}
+		return b
+	}
+	dataOffset := uint32(8)
+	entries := []entry{
+		{tagImageWidth, tiffLong, long(uint32(width))},
+		{tagImageLength, tiffLong, long(uint32(height))},
+		{tagBitsPerSample, tiffShort, short(32)},
+		{tagCompression, tiffShort, short(compressionLZW)},
+		{tagStripOffsets, tiffLong, long(dataOffset)},
+		{tagSamplesPerPixel, tiffShort, short(1)},
+		{tagRowsPerStrip, tiffLong, long(uint32(height))},
+		{tagStripByteCounts, tiffLong, long(uint32(len(compressed)))},
+		{tagSampleFormat, tiffShort, short(sampleFormatFloat)},
+		{tagModelPixelScale, tiffDouble, double(scale, scale, 0)},
+		{tagModelTiepoint, tiffDouble, double(0, 0, 0, west, north, 0)},
+		{tagGeoKeyDirectory, tiffShort, short(1, 1, 0, 2, geoKeyModelType, 0, 1, modelTypeGeographic, geoKeyGeographicType, 0, 1, epsgWGS84)},
+		{tagGDALNoData, tiffASCII, []byte("-9999\x00")},
+	}
+
+	out := append([]byte("MM\x00\x2a"), long(dataOffset+uint32(len(compressed)))...)
+	out = append(out, compressed...)
+	// values larger than 4 bytes follow the directory
+	extra := len(out) + 2 + 12*len(entries) + 4
+	var values []byte
+	out = append(out, short(uint16(len(entries)))...)
+	for _, e := range entries {
+		count := len(e.values) / map[uint16]int{tiffASCII: 1, tiffShort: 2, tiffLong: 4, tiffDouble: 8}[e.typ]
+		out = append(out, short(e.tag, e.typ)...)
+		out = append(out, long(uint32(count))...)
+		if len(e.values) > 4 {
+			out = append(out, long(uint32(extra+len(values)))...)
+			values = append(values, e.values...)
+		} else {
+			out = append(out, append(e.values, make([]byte, 4-len(e.values))...)...)
+		}
+	}
+	out = append(out, long(0)...)
+	return append(out, values...)
+}
+
+func TestDecodeGeoTIFF(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x - y) })
+	img.Data[1201*5+7] = VoidValue
+
+	for _, options := range []GeoTIFFOptions{{}, {Deflate: true}, {TileSize: 256}, {Deflate: true, TileSize: 512}} {
+		var buf bytes.Buffer
+		if err := img.EncodeGeoTIFF(&buf, options); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeGeoTIFF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalImages(img, decoded) || decoded.IsMosaic() {
+			t.Error("GeoTIFF should decode to the encoded image with options", options, "but returned", decoded.Format, decoded.Tile)
+		}
+	}
+
+	mosaic, err := Mosaic(img, newTestImage(Tile{48, 13}, func(x, y int) int16 { return int16(y) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := mosaic.EncodeGeoTIFF(&buf, GeoTIFFOptions{Deflate: true}); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeGeoTIFF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Tile != mosaic.Tile || decoded.Columns != 2 || decoded.Rows != 1 || !equalImages(mosaic, decoded) {
+		t.Error("GeoTIFF of a mosaic should decode to the mosaic, but returned", decoded.Tile, decoded.Columns, decoded.Rows)
+	}
+}
+
+func TestDecodeGeoTIFFFloat(t *testing.T) {
+	// 61x41 pixels of 3 arc seconds, whose centers lie on the SRTM3 grid of N48E012
+	const width, height, scale = 61, 41, 1.0 / 1200
+	samples := make([]float32, width*height)
+	for i := range samples {
+		samples[i] = float32(i%width+i/width) + 0.25
+	}
+	samples[3] = float32(math.NaN())
+	samples[width+1] = -9999
+
+	data := floatTestTIFF(samples, width, height, 12-scale/2, 49+scale/2, scale)
+	name := filepath.Join(t.TempDir(), "dem.tif")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img, err := OpenSRTMImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM3Format || img.Tile != (Tile{48, 12}) || img.IsMosaic() {
+		t.Fatal("GeoTIFF should cover the SRTM3 tile N48E012, but returned", img.Format, img.Tile)
+	}
+	for _, p := range []struct {
+		x, y      int
+		elevation int16
+	}{{0, 0, 0}, {10, 20, 30}, {60, 40, 100}, {3, 0, VoidValue}, {1, 1, VoidValue}, {61, 0, VoidValue}, {0, 41, VoidValue}, {600, 600, VoidValue}} {
+		if e := img.Data[p.y*img.Width()+p.x]; e != p.elevation {
+			t.Error("elevation at", p.x, p.y, "should be", p.elevation, "but was", e)
+		}
+	}
+
+	data[0] = 'X'
+	if _, err := DecodeGeoTIFF(bytes.NewReader(data)); !errors.Is(err, ErrUnsupportedGeoTIFF) {
+		t.Error("invalid header should return an ErrUnsupportedGeoTIFF error, but returned", err)
+	}
+}
+
+func TestGeoTIFFSizeLimits(t *testing.T) {
+	// mosaics are readable up to MaxMosaicSamples, so larger ones are not written
+	huge := &SRTMImage{Format: SRTM3Format, Tile: Tile{0, 0}, TileKnown: true, Columns: 13, Rows: 13}
+	encoders := map[string]func() error{
+		"EncodeGeoTIFF":   func() error { return huge.EncodeGeoTIFF(io.Discard, GeoTIFFOptions{}) },
+		"EncodeASCIIGrid": func() error { return huge.EncodeASCIIGrid(io.Discard) },
+		"EncodeXYZ":       func() error { return huge.EncodeXYZ(io.Discard) },
+	}
+	for name, encode := range encoders {
+		if err := encode(); !errors.Is(err, ErrMosaicTooLarge) {
+			t.Error(name, "of 13x13 SRTM3 tiles should return an ErrMosaicTooLarge error, but returned", err)
+		}
+	}
+
+	// a chunk inflating beyond its expected size is cut off
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(make([]byte, 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	dir := &tiffDirectory{ints: map[uint16][]uint64{tagCompression: {compressionDeflate}}}
+	raw, err := dir.decompress(compressed.Bytes(), 8)
+	if err != nil || len(raw) != 8 {
+		t.Error("decompress should stop after 8 bytes, but returned", len(raw), err)
+	}
+}
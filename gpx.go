@@ -0,0 +1,363 @@
+package srtm
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidGPX = errors.New("invalid GPX")
+
+// GPXOptions configure Dataset.EnrichGPX.
+type GPXOptions struct {
+	// Interpolation is used to sample the elevation of the points.
+	Interpolation Interpolation
+	// Smoothing is the length in meters of a moving average applied to the elevations
+	// along each track segment and route. 0 disables smoothing.
+	Smoothing float64
+}
+
+// ElevationGain is the total climb and drop along tracks and routes in meters.
+type ElevationGain struct {
+	Ascent  float64
+	Descent float64
+}
+
+// GPXSummary reports the changes made by Dataset.EnrichGPX.
+type GPXSummary struct {
+	// Points is the number of track, route and waypoints.
+	Points int
+	// Corrected is the number of points whose elevation was taken from the dataset.
+	// The other points are on voids or missing tiles and keep their original elevation.
+	Corrected int
+	// Before is the elevation gain of the original elevations, ignoring points without elevation.
+	Before ElevationGain
+	// After is the elevation gain of the written elevations.
+	After ElevationGain
+}
+
+// gpxPoint is a trkpt, rtept or wpt element of a GPX document.
+type gpxPoint struct {
+	LatLon
+	start int // index of the start element in the token list
+	// sequence numbers the track segment or route of the point, waypoints have -1
+	sequence  int
+	elevation float64
+	hasEle    bool
+	corrected float64
+	ok        bool // corrected is valid
+}
+
+// EnrichGPX reads a GPX 1.1 document from r and writes it to w, with the <ele> element of every
+// track, route and waypoint set to the elevation of the dataset. Missing <ele> elements are added.
+// All other content of the document, including extensions and comments, is written unchanged.
+// Points on voids or missing tiles keep their original elevation.
+func (d *Dataset) EnrichGPX(w io.Writer, r io.Reader, options GPXOptions) (*GPXSummary, error) {
+	tokens, points, err := readGPX(r)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &GPXSummary{Points: len(points)}
+	for i := range points {
+		p := &points[i]
+		p.corrected, err = d.ElevationAt(p.Lat, p.Lon, options.Interpolation)
+		switch {
+		case err == nil || errors.Is(err, ErrOcean):
+			p.ok = true
+			summary.Corrected++
+		case !errors.Is(err, ErrVoid) && !errors.Is(err, ErrTileNotFound):
+			return nil, err
+		}
+	}
+	if options.Smoothing > 0 {
+		forEachSequence(points, func(sequence []gpxPoint) {
+			smoothElevations(sequence, options.Smoothing)
+		})
+	}
+
+	forEachSequence(points, func(sequence []gpxPoint) {
+		var before, after []float64
+		for _, p := range sequence {
+			if p.hasEle {
+				before = append(before, p.elevation)
+			}
+			if p.ok {
+				after = append(after, p.corrected)
+			} else if p.hasEle {
+				after = append(after, p.elevation)
+			}
+		}
+		summary.Before.add(before)
+		summary.After.add(after)
+	})
+
+	if err := writeGPX(w, tokens, points); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// add sums the climbs and drops between the consecutive elevations.
+func (g *ElevationGain) add(elevations []float64) {
+	for i := 1; i < len(elevations); i++ {
+		if rise := elevations[i] - elevations[i-1]; rise > 0 {
+			g.Ascent += rise
+		} else {
+			g.Descent -= rise
+		}
+	}
+}
+
+// forEachSequence calls fn with the points of every track segment and route.
+func forEachSequence(points []gpxPoint, fn func([]gpxPoint)) {
+	for start := 0; start < len(points); {
+		end := start + 1
+		for end < len(points) && points[end].sequence == points[start].sequence {
+			end++
+		}
+		if points[start].sequence >= 0 {
+			fn(points[start:end])
+		}
+		start = end
+	}
+}
+
+// smoothElevations replaces the corrected elevations by their average over the given distance
+// along the sequence, centered on each point.
+func smoothElevations(points []gpxPoint, window float64) {
+	distances := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		distances[i] = distances[i-1] + GreatCircleDistance(points[i-1].LatLon, points[i].LatLon)
+	}
+	smoothed := make([]float64, len(points))
+	for i := range points {
+		var sum float64
+		n := 0
+		for j := i; j >= 0 && distances[i]-distances[j] <= window/2; j-- {
+			if points[j].ok {
+				sum += points[j].corrected
+				n++
+			}
+		}
+		for j := i + 1; j < len(points) && distances[j]-distances[i] <= window/2; j++ {
+			if points[j].ok {
+				sum += points[j].corrected
+				n++
+			}
+		}
+		if n > 0 {
+			smoothed[i] = sum / float64(n)
+		}
+	}
+	for i := range points {
+		if points[i].ok {
+			points[i].corrected = smoothed[i]
+		}
+	}
+}
+
+// isGPXPoint reports whether the element is a point with an elevation.
+func isGPXPoint(name xml.Name) bool {
+	return name.Local == "trkpt" || name.Local == "rtept" || name.Local == "wpt"
+}
+
+// readGPX returns all tokens of the document and its points.
+// Raw tokens are used, so that namespace prefixes are kept as written.
+func readGPX(r io.Reader) ([]xml.Token, []gpxPoint, error) {
+	decoder := xml.NewDecoder(r)
+	var tokens []xml.Token
+	var points []gpxPoint
+	var stack []string
+	sequence := -1
+	current := -1 // index of the point the decoder is in, if any
+	var ele strings.Builder
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGPX, err)
+		}
+		tok = xml.CopyToken(tok)
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			switch {
+			case t.Name.Local == "trkseg" || t.Name.Local == "rte":
+				sequence++
+			case isGPXPoint(t.Name) && current < 0:
+				p := gpxPoint{start: len(tokens), sequence: -1}
+				if t.Name.Local != "wpt" {
+					p.sequence = sequence
+				}
+				hasLat, hasLon := false, false
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "lat":
+						p.Lat, err = strconv.ParseFloat(strings.TrimSpace(attr.Value), 64)
+						hasLat = true
+					case "lon":
+						p.Lon, err = strconv.ParseFloat(strings.TrimSpace(attr.Value), 64)
+						hasLon = true
+					}
+					if err != nil {
+						return nil, nil, fmt.Errorf("%w: line %v: %v %v", ErrInvalidGPX, lineOf(decoder), attr.Name.Local, err)
+					}
+				}
+				if !hasLat || !hasLon {
+					return nil, nil, fmt.Errorf("%w: line %v: point %v: %v without lat and lon", ErrInvalidGPX, lineOf(decoder), len(points), t.Name.Local)
+				}
+				current = len(points)
+				points = append(points, p)
+			case t.Name.Local == "ele" && current >= 0 && len(stack) >= 2 && isGPXPoint(xml.Name{Local: stack[len(stack)-2]}):
+				ele.Reset()
+			}
+		case xml.CharData:
+			if current >= 0 && len(stack) > 0 && stack[len(stack)-1] == "ele" {
+				ele.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, nil, fmt.Errorf("%w: line %v: unexpected end element %v", ErrInvalidGPX, lineOf(decoder), t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+			switch {
+			case t.Name.Local == "ele" && current >= 0 && len(stack) > 0 && isGPXPoint(xml.Name{Local: stack[len(stack)-1]}):
+				v, err := strconv.ParseFloat(strings.TrimSpace(ele.String()), 64)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%w: line %v: ele %v", ErrInvalidGPX, lineOf(decoder), err)
+				}
+				points[current].elevation, points[current].hasEle = v, true
+			case isGPXPoint(t.Name) && current >= 0 && !inPoint(stack):
+				current = -1
+			}
+		}
+		tokens = append(tokens, tok)
+	}
+	if len(stack) > 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected end of document in %v", ErrInvalidGPX, stack[len(stack)-1])
+	}
+	return tokens, points, nil
+}
+
+// inPoint reports whether the element stack contains a point.
+func inPoint(stack []string) bool {
+	for _, name := range stack {
+		if isGPXPoint(xml.Name{Local: name}) {
+			return true
+		}
+	}
+	return false
+}
+
+func lineOf(decoder *xml.Decoder) int {
+	line, _ := decoder.InputPos()
+	return line
+}
+
+// writeGPX writes the tokens with the elevations of the corrected points replaced.
+// The <ele> element is the first child of a point in GPX 1.1, so it is written before any other child.
+func writeGPX(w io.Writer, tokens []xml.Token, points []gpxPoint) error {
+	encoder := xml.NewEncoder(w)
+	next := 0        // next point
+	point := -1      // point whose children are written
+	depth := 0       // depth below the point
+	pending := false // the <ele> of the point is yet to be written
+	skip := false    // inside the replaced <ele>
+
+	writeEle := func(name xml.Name, value float64) error {
+		start := xml.StartElement{Name: name}
+		text := strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+		for _, tok := range []xml.Token{start, xml.CharData(text), start.End()} {
+			if err := encoder.EncodeToken(tok); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, tok := range tokens {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t.Name = rawName(t.Name)
+			for k := range t.Attr {
+				t.Attr[k].Name = rawName(t.Attr[k].Name)
+			}
+			tok = t
+			if point >= 0 {
+				depth++
+				if depth == 1 && points[point].ok {
+					ele := elementName(tokens[points[point].start], "ele")
+					if pending {
+						pending = false
+						if err := writeEle(ele, points[point].corrected); err != nil {
+							return err
+						}
+					}
+					// the original <ele> is replaced by the one written before
+					if t.Name == ele {
+						skip = true
+						continue
+					}
+				}
+			}
+			if next < len(points) && points[next].start == i {
+				point = next
+				next++
+				depth = 0
+				pending = points[point].ok
+			}
+		case xml.EndElement:
+			t.Name = rawName(t.Name)
+			tok = t
+			if point >= 0 {
+				if depth == 0 {
+					if pending {
+						if err := writeEle(elementName(tokens[points[point].start], "ele"), points[point].corrected); err != nil {
+							return err
+						}
+					}
+					point, pending = -1, false
+				} else {
+					depth--
+					if skip && depth == 0 {
+						skip = false
+						continue
+					}
+				}
+			}
+		}
+		if skip {
+			continue
+		}
+		if err := encoder.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+	return encoder.Flush()
+}
+
+// rawName joins the namespace prefix of a raw token with the local name,
+// so that the encoder writes the name as read.
+func rawName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
+
+func prefixOf(tok xml.Token) string {
+	return tok.(xml.StartElement).Name.Space
+}
+
+// elementName returns the name of a child element of the start element in its namespace.
+func elementName(tok xml.Token, local string) xml.Name {
+	return rawName(xml.Name{Space: prefixOf(tok), Local: local})
+}
@@ -0,0 +1,105 @@
+package srtm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <wpt lat="48.5" lon="12.5"><name>summit</name></wpt>
+  <trk>
+    <name>ride</name>
+    <trkseg>
+      <trkpt lat="48.5" lon="12.25"><ele>310</ele><time>2024-05-01T10:00:00Z</time></trkpt>
+      <trkpt lat="48.5" lon="12.5"><ele>590</ele><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="48.5" lon="12.75"><ele>280</ele></trkpt>
+      <trkpt lat="49.5" lon="12.75"><ele>700</ele></trkpt>
+    </trkseg>
+  </trk>
+  <rte><rtept lat="48.5" lon="12.75"></rtept></rte>
+</gpx>
+`
+
+func TestDatasetEnrichGPX(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(600 - math.Abs(float64(x-600))) }))
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	summary, err := dataset.EnrichGPX(&buf, strings.NewReader(testGPX), GPXOptions{Interpolation: Bilinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Points != 6 || summary.Corrected != 5 {
+		t.Error("summary should report 5 of 6 points corrected, but was", summary.Points, summary.Corrected)
+	}
+	if summary.Before != (ElevationGain{Ascent: 700, Descent: 310}) {
+		t.Error("summary before should ascend 700 and descend 310 meters, but was", summary.Before)
+	}
+	// the point on the missing tile keeps its elevation
+	if summary.After != (ElevationGain{Ascent: 700, Descent: 300}) {
+		t.Error("summary after should ascend 700 and descend 300 meters, but was", summary.After)
+	}
+
+	var gpx struct {
+		Waypoints []struct {
+			Ele  *float64 `xml:"ele"`
+			Name string   `xml:"name"`
+		} `xml:"wpt"`
+		Points []struct {
+			Ele  []float64 `xml:"ele"`
+			Time string    `xml:"time"`
+			HR   int       `xml:"extensions>TrackPointExtension>hr"`
+		} `xml:"trk>trkseg>trkpt"`
+		RoutePoints []struct {
+			Ele *float64 `xml:"ele"`
+		} `xml:"rte>rtept"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &gpx); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if len(gpx.Waypoints) != 1 || gpx.Waypoints[0].Ele == nil || *gpx.Waypoints[0].Ele != 600 || gpx.Waypoints[0].Name != "summit" {
+		t.Error("waypoint should get an elevation of 600 meters, but was", buf.String())
+	}
+	expected := []float64{300, 600, 300, 700}
+	for i, p := range gpx.Points {
+		if len(p.Ele) != 1 || p.Ele[0] != expected[i] {
+			t.Error("track point", i, "should have the elevation", expected[i], "but had", p.Ele)
+		}
+	}
+	if gpx.Points[0].Time != "2024-05-01T10:00:00Z" || gpx.Points[1].HR != 150 {
+		t.Error("EnrichGPX should keep other elements and extensions, but wrote", buf.String())
+	}
+	if len(gpx.RoutePoints) != 1 || gpx.RoutePoints[0].Ele == nil || *gpx.RoutePoints[0].Ele != 300 {
+		t.Error("route point should get an elevation of 300 meters, but was", buf.String())
+	}
+	if !strings.Contains(buf.String(), `xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"`) || !strings.Contains(buf.String(), "<gpxtpx:hr>150</gpxtpx:hr>") {
+		t.Error("EnrichGPX should keep namespace prefixes, but wrote", buf.String())
+	}
+
+	// smoothing over the whole track flattens the ridge
+	summary, err = dataset.EnrichGPX(&buf, strings.NewReader(testGPX), GPXOptions{Interpolation: Bilinear, Smoothing: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.After.Descent >= 300 {
+		t.Error("smoothing should reduce the descent, but was", summary.After.Descent)
+	}
+
+	_, err = dataset.EnrichGPX(&buf, strings.NewReader(`<gpx><wpt lat="x" lon="1"/></gpx>`), GPXOptions{})
+	if !errors.Is(err, ErrInvalidGPX) {
+		t.Error("EnrichGPX with invalid coordinates should return an ErrInvalidGPX error, but returned", err)
+	}
+	_, err = dataset.EnrichGPX(&buf, strings.NewReader(`<gpx><trk><trkseg><trkpt lat="48.5" lon="12.5"/><trkpt lat="48.5"/></trkseg></trk></gpx>`), GPXOptions{})
+	if !errors.Is(err, ErrInvalidGPX) || !strings.Contains(err.Error(), "point 1") {
+		t.Error("EnrichGPX with a point without lon should return an ErrInvalidGPX error, but returned", err)
+	}
+}
@@ -0,0 +1,117 @@
+package srtm
+
+import (
+	"image"
+	"math"
+)
+
+// HillshadeOptions configure the hillshade images.
+type HillshadeOptions struct {
+	// Azimuth is the direction of the sun in degrees clockwise from north.
+	// It is ignored by MultidirectionalHillshadeImage.
+	Azimuth float64
+	// Altitude is the angle of the sun above the horizon in degrees.
+	Altitude float64
+	// ZFactor exaggerates the elevation. A ZFactor of 0 is treated as 1.
+	ZFactor float64
+	// Algorithm computes the surface gradient.
+	Algorithm GradientAlgorithm
+}
+
+// DefaultHillshadeOptions lights the terrain from the north-west at 45 degrees above the horizon,
+// the cartographic convention which avoids the relief inversion effect.
+var DefaultHillshadeOptions = HillshadeOptions{Azimuth: 315, Altitude: 45, ZFactor: 1, Algorithm: Horn}
+
+// multidirectionalAzimuths are the sun directions combined by MultidirectionalHillshadeImage.
+var multidirectionalAzimuths = [4]float64{225, 270, 315, 360}
+
+// HillshadeImage returns the terrain as lit by the sun from a single direction.
+// Terrain facing the sun is white, terrain in its own shadow is black.
+// Samples next to voids are black as well. Images with an unknown tile return ErrUnknownTile,
+// as the ground distance between samples depends on the latitude.
+func (srtmImg *SRTMImage) HillshadeImage(options HillshadeOptions) (*image.Gray, error) {
+	light := sunVector(options.Azimuth, options.Altitude)
+	return srtmImg.hillshade(options, func(normal [3]float64, dzdx, dzdn float64) float64 {
+		return dot(normal, light)
+	})
+}
+
+// MultidirectionalHillshadeImage combines the light of four suns in the west, north-west, north and
+// south-west, weighting each sun by how perpendicular it shines onto the slope. This reveals terrain
+// features of every orientation, which are lost with a single sun running parallel to them.
+func (srtmImg *SRTMImage) MultidirectionalHillshadeImage(options HillshadeOptions) (*image.Gray, error) {
+	var lights [4][3]float64
+	for i, azimuth := range multidirectionalAzimuths {
+		lights[i] = sunVector(azimuth, options.Altitude)
+	}
+	return srtmImg.hillshade(options, func(normal [3]float64, dzdx, dzdn float64) float64 {
+		aspect := math.Atan2(-dzdx, -dzdn)
+		var shade, weightSum float64
+		for i, azimuth := range multidirectionalAzimuths {
+			weight := 1.0
+			if dzdx != 0 || dzdn != 0 {
+				s := math.Sin(aspect - azimuth*math.Pi/180)
+				weight = s * s
+			}
+			shade += weight * math.Max(0, dot(normal, lights[i]))
+			weightSum += weight
+		}
+		if weightSum == 0 {
+			return 0
+		}
+		return shade / weightSum
+	})
+}
+
+// CombinedHillshadeImage darkens the single direction hillshade by the slope,
+// so that flat terrain is bright and steep terrain stands out independent of the sun.
+func (srtmImg *SRTMImage) CombinedHillshadeImage(options HillshadeOptions) (*image.Gray, error) {
+	light := sunVector(options.Azimuth, options.Altitude)
+	return srtmImg.hillshade(options, func(normal [3]float64, dzdx, dzdn float64) float64 {
+		// angle between the surface normal and the sun, scaled by the slope angle
+		angle := math.Acos(math.Max(-1, math.Min(1, dot(normal, light))))
+		slope := math.Atan(math.Hypot(dzdx, dzdn))
+		return 1 - angle*slope/(math.Pi*math.Pi/4)
+	})
+}
+
+// hillshade converts the brightness computed by shade for every surface normal into an image.
+// The gradient passed to shade already includes the z factor.
+func (srtmImg *SRTMImage) hillshade(options HillshadeOptions, shade func(normal [3]float64, dzdx, dzdn float64) float64) (*image.Gray, error) {
+	zFactor := options.ZFactor
+	if zFactor == 0 {
+		zFactor = 1
+	}
+
+	img := image.NewGray(image.Rect(0, 0, srtmImg.Width(), srtmImg.Height()))
+	err := srtmImg.gradients(options.Algorithm, func(i int, dzdx, dzdn float64, ok bool) {
+		if !ok {
+			return
+		}
+		dzdx, dzdn = dzdx*zFactor, dzdn*zFactor
+		length := math.Sqrt(dzdx*dzdx + dzdn*dzdn + 1)
+		normal := [3]float64{-dzdx / length, -dzdn / length, 1 / length}
+
+		v := shade(normal, dzdx, dzdn) * 255
+		if v < 0 {
+			v = 0
+		} else if v > 255 {
+			v = 255
+		}
+		img.Pix[i] = uint8(v + 0.5)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// sunVector returns the unit vector pointing towards the sun in east, north, up coordinates.
+func sunVector(azimuth, altitude float64) [3]float64 {
+	az, alt := azimuth*math.Pi/180, altitude*math.Pi/180
+	return [3]float64{math.Sin(az) * math.Cos(alt), math.Cos(az) * math.Cos(alt), math.Sin(alt)}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
@@ -0,0 +1,70 @@
+package srtm
+
+import (
+	"testing"
+)
+
+func TestHillshadeImage(t *testing.T) {
+	flat := newTestImage(Tile{0, 0}, func(x, y int) int16 { return 0 })
+	img, err := flat.HillshadeImage(DefaultHillshadeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flat terrain receives sin(altitude) of the light
+	if v := img.GrayAt(600, 600).Y; v != 180 {
+		t.Error("flat terrain lit at 45 degrees should have brightness 180, but had", v)
+	}
+
+	// terrain rising towards the east faces west, so a western sun lights it better than an eastern one
+	slope := newTestImage(Tile{0, 0}, func(x, y int) int16 { return int16(x * 50) })
+	west, err := slope.HillshadeImage(HillshadeOptions{Azimuth: 270, Altitude: 45})
+	if err != nil {
+		t.Fatal(err)
+	}
+	east, err := slope.HillshadeImage(HillshadeOptions{Azimuth: 90, Altitude: 45})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if west.GrayAt(600, 600).Y <= east.GrayAt(600, 600).Y {
+		t.Error("a western sun should light a west facing slope brighter than an eastern sun")
+	}
+	exaggerated, err := slope.HillshadeImage(HillshadeOptions{Azimuth: 90, Altitude: 45, ZFactor: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exaggerated.GrayAt(600, 600).Y >= east.GrayAt(600, 600).Y {
+		t.Error("exaggerating the slope should darken the side facing away from the sun")
+	}
+}
+
+func TestHillshadeVariants(t *testing.T) {
+	img := newTestImage(Tile{0, 0}, func(x, y int) int16 { return int16(x*20 + y*10) })
+	img.Data[600*1201+600] = VoidValue
+
+	multi, err := img.MultidirectionalHillshadeImage(DefaultHillshadeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, err := img.CombinedHillshadeImage(DefaultHillshadeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if multi.Bounds() != img.FullImage().Bounds() || combined.Bounds() != multi.Bounds() {
+		t.Error("hillshade images should match the dimensions of the tile")
+	}
+	if multi.GrayAt(600, 600).Y != 0 || combined.GrayAt(601, 601).Y != 0 {
+		t.Error("hillshade next to voids should be black")
+	}
+	if multi.GrayAt(100, 100).Y == 0 || combined.GrayAt(100, 100).Y == 0 {
+		t.Error("hillshade of lit terrain should not be black")
+	}
+
+	flat := newTestImage(Tile{0, 0}, func(x, y int) int16 { return 0 })
+	flatCombined, err := flat.CombinedHillshadeImage(DefaultHillshadeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := flatCombined.GrayAt(10, 10).Y; v != 255 {
+		t.Error("combined hillshade of flat terrain should be white, but was", v)
+	}
+}
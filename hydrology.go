@@ -0,0 +1,345 @@
+package srtm
+
+import (
+	"container/heap"
+	"image"
+	"io"
+	"math"
+)
+
+// FlowDirection is the D8 direction water flows from a sample to one of its eight neighbours,
+// encoded as powers of two clockwise from the east as in ESRI's flow direction rasters.
+type FlowDirection uint8
+
+const (
+	// NoFlow marks outlets, i.e. samples on the edge or next to voids without lower neighbours, and voids.
+	NoFlow        = FlowDirection(0)
+	FlowEast      = FlowDirection(1)
+	FlowSouthEast = FlowDirection(2)
+	FlowSouth     = FlowDirection(4)
+	FlowSouthWest = FlowDirection(8)
+	FlowWest      = FlowDirection(16)
+	FlowNorthWest = FlowDirection(32)
+	FlowNorth     = FlowDirection(64)
+	FlowNorthEast = FlowDirection(128)
+)
+
+// d8 lists the neighbours of a sample clockwise from the east with their flow direction.
+var d8 = [8]struct {
+	dx, dy    int
+	direction FlowDirection
+}{
+	{1, 0, FlowEast}, {1, 1, FlowSouthEast}, {0, 1, FlowSouth}, {-1, 1, FlowSouthWest},
+	{-1, 0, FlowWest}, {-1, -1, FlowNorthWest}, {0, -1, FlowNorth}, {1, -1, FlowNorthEast},
+}
+
+// Offset returns the offset to the neighbour the direction points to.
+func (f FlowDirection) Offset() image.Point {
+	for _, n := range d8 {
+		if n.direction == f {
+			return image.Point{n.dx, n.dy}
+		}
+	}
+	return image.Point{}
+}
+
+// FlowDirections is a raster of D8 flow directions in the layout of the elevation data.
+type FlowDirections struct {
+	Data   []FlowDirection
+	Width  int
+	Height int
+}
+
+// At returns the flow direction at the given coordinates.
+func (f *FlowDirections) At(point image.Point) FlowDirection {
+	return f.Data[point.Y*f.Width+point.X]
+}
+
+// downstream returns the index of the sample the water of sample i flows to, or -1 for outlets.
+func (f *FlowDirections) downstream(i int) int {
+	if f.Data[i] == NoFlow {
+		return -1
+	}
+	offset := f.Data[i].Offset()
+	return i + offset.Y*f.Width + offset.X
+}
+
+// FillDepressions raises the elevation of all depressions, which have no outflow, to their spill point,
+// so that water can flow from every sample to the edge of the image or to a void.
+// Filled areas get a minimal slope towards their outlet, the next float32 value per sample,
+// so that flow directions are defined on them as well. Voids are NaN.
+//
+// The priority-flood algorithm of Barnes, Lehman and Mulla is used,
+// which processes the samples from the outlets upwards in O(n log n).
+func (srtmImg *SRTMImage) FillDepressions() *Float32Grid {
+	width, height := srtmImg.Width(), srtmImg.Height()
+	filled := NewFloat32Grid(width, height)
+	closed := make([]bool, len(srtmImg.Data))
+	open := &elevationQueue{}
+	var pit []int
+
+	for i, v := range srtmImg.Data {
+		if v == VoidValue {
+			filled.Data[i] = float32(math.NaN())
+			closed[i] = true
+			continue
+		}
+		filled.Data[i] = float32(v)
+	}
+	// outlets are the samples on the edge and next to voids
+	for i, v := range srtmImg.Data {
+		if v == VoidValue {
+			continue
+		}
+		x, y := i%width, i/width
+		outlet := x == 0 || y == 0 || x == width-1 || y == height-1
+		for _, n := range d8 {
+			if outlet {
+				break
+			}
+			outlet = srtmImg.Data[(y+n.dy)*width+x+n.dx] == VoidValue
+		}
+		if outlet {
+			closed[i] = true
+			heap.Push(open, elevationItem{i, filled.Data[i]})
+		}
+	}
+
+	for open.Len() > 0 || len(pit) > 0 {
+		var c int
+		if len(pit) > 0 {
+			c, pit = pit[0], pit[1:]
+		} else {
+			c = heap.Pop(open).(elevationItem).index
+		}
+		x, y := c%width, c/width
+		for _, n := range d8 {
+			nx, ny := x+n.dx, y+n.dy
+			if nx < 0 || ny < 0 || nx >= width || ny >= height {
+				continue
+			}
+			i := ny*width + nx
+			if closed[i] {
+				continue
+			}
+			closed[i] = true
+			if minimum := math.Nextafter32(filled.Data[c], float32(math.Inf(1))); filled.Data[i] <= minimum {
+				filled.Data[i] = minimum
+				pit = append(pit, i)
+			} else {
+				heap.Push(open, elevationItem{i, filled.Data[i]})
+			}
+		}
+	}
+	return filled
+}
+
+type elevationItem struct {
+	index     int
+	elevation float32
+}
+
+// elevationQueue is a min-heap of samples by elevation.
+type elevationQueue []elevationItem
+
+func (q elevationQueue) Len() int            { return len(q) }
+func (q elevationQueue) Less(i, j int) bool  { return q[i].elevation < q[j].elevation }
+func (q elevationQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *elevationQueue) Push(x interface{}) { *q = append(*q, x.(elevationItem)) }
+func (q *elevationQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// FlowDirections returns the D8 flow direction of every sample, which points to the neighbour
+// with the steepest descent. Depressions are filled with FillDepressions first, so that all water
+// reaches an outlet. The ground distance to the neighbours is derived from the latitude of each row,
+// so images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) FlowDirections() (*FlowDirections, error) {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return nil, err
+	}
+	width, height := srtmImg.Width(), srtmImg.Height()
+	filled := srtmImg.FillDepressions()
+	spacing := 1 / float64(srtmImg.Format.Size()-1)
+	flow := &FlowDirections{Data: make([]FlowDirection, len(srtmImg.Data)), Width: width, Height: height}
+
+	for y := 0; y < height; y++ {
+		lat, _ := srtmImg.PointToLatLon(image.Point{0, y})
+		latMeters, lonMeters := metersPerDegree(lat)
+		dx, dy := spacing*lonMeters, spacing*latMeters
+		var distances [8]float64
+		for k, n := range d8 {
+			distances[k] = math.Hypot(float64(n.dx)*dx, float64(n.dy)*dy)
+		}
+
+		for x := 0; x < width; x++ {
+			z := filled.Data[y*width+x]
+			if math.IsNaN(float64(z)) {
+				continue
+			}
+			steepest := 0.0
+			for k, n := range d8 {
+				nx, ny := x+n.dx, y+n.dy
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				zn := filled.Data[ny*width+nx]
+				if math.IsNaN(float64(zn)) {
+					continue
+				}
+				if slope := float64(z-zn) / distances[k]; slope > steepest {
+					steepest = slope
+					flow.Data[y*width+x] = n.direction
+				}
+			}
+		}
+	}
+	return flow, nil
+}
+
+// FlowAccumulation returns the number of samples draining through every sample
+// along the flow directions, including the sample itself. Voids are NaN.
+func (srtmImg *SRTMImage) FlowAccumulation(f *FlowDirections) *Float32Grid {
+	grid := NewFloat32Grid(f.Width, f.Height)
+	inflow := make([]int, len(f.Data))
+	for i := range f.Data {
+		if j := f.downstream(i); j >= 0 {
+			inflow[j]++
+		}
+	}
+
+	// visit the samples from their sources downstream
+	var queue []int
+	for i, v := range srtmImg.Data {
+		if v == VoidValue {
+			grid.Data[i] = float32(math.NaN())
+			continue
+		}
+		grid.Data[i] = 1
+		if inflow[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if j := f.downstream(i); j >= 0 {
+			grid.Data[j] += grid.Data[i]
+			inflow[j]--
+			if inflow[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	return grid
+}
+
+// Stream is a section of a drainage network between sources, confluences and outlets,
+// in fractional sample coordinates from upstream to downstream.
+type Stream struct {
+	Points []PointF
+	// Accumulation is the number of samples draining through the last sample of the stream
+	// above the confluence.
+	Accumulation float32
+	// Order is the Strahler stream order. Sources have order 1,
+	// and the confluence of two streams of the same order increases it by one.
+	Order int
+}
+
+// Streams extracts the drainage network of all samples with an accumulation of at least threshold.
+// Each stream ends at the confluence with another stream, which it shares as last point,
+// or at an outlet.
+func (f *FlowDirections) Streams(accumulation *Float32Grid, threshold float32) []Stream {
+	isStream := func(i int) bool {
+		return i >= 0 && accumulation.Data[i] >= threshold
+	}
+	inflow := make([]int, len(f.Data))
+	for i := range f.Data {
+		if isStream(i) {
+			if j := f.downstream(i); isStream(j) {
+				inflow[j]++
+			}
+		}
+	}
+
+	// Strahler order, from the sources downstream
+	order := make([]int, len(f.Data))
+	maxCount := make([]int, len(f.Data)) // number of upstream streams with the maximum order
+	remaining := make([]int, len(inflow))
+	copy(remaining, inflow)
+	var queue []int
+	for i := range f.Data {
+		if isStream(i) && inflow[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		switch {
+		case inflow[i] == 0:
+			order[i] = 1
+		case maxCount[i] > 1:
+			order[i]++
+		}
+		j := f.downstream(i)
+		if !isStream(j) {
+			continue
+		}
+		if order[i] > order[j] {
+			order[j], maxCount[j] = order[i], 1
+		} else if order[i] == order[j] {
+			maxCount[j]++
+		}
+		remaining[j]--
+		if remaining[j] == 0 {
+			queue = append(queue, j)
+		}
+	}
+
+	var streams []Stream
+	for start := range f.Data {
+		if !isStream(start) || inflow[start] == 1 {
+			continue
+		}
+		stream := Stream{Order: order[start]}
+		i := start
+		for {
+			stream.Points = append(stream.Points, PointF{float64(i % f.Width), float64(i / f.Width)})
+			stream.Accumulation = accumulation.Data[i]
+			j := f.downstream(i)
+			if !isStream(j) {
+				break
+			}
+			if inflow[j] != 1 {
+				stream.Points = append(stream.Points, PointF{float64(j % f.Width), float64(j / f.Width)})
+				break
+			}
+			i = j
+		}
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+// WriteStreamsGeoJSON writes the streams as a GeoJSON FeatureCollection of LineStrings
+// in WGS84 longitude/latitude, with the properties accumulation and order.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) WriteStreamsGeoJSON(w io.Writer, streams []Stream) error {
+	features := make([]geoJSONFeature, len(streams))
+	for i, s := range streams {
+		coordinates, err := srtmImg.lonLatCoordinates(s.Points)
+		if err != nil {
+			return err
+		}
+		features[i] = geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: map[string]interface{}{"accumulation": s.Accumulation, "order": s.Order},
+		}
+	}
+	return writeGeoJSON(w, features)
+}
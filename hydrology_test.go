@@ -0,0 +1,120 @@
+package srtm
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestFillDepressions(t *testing.T) {
+	// a valley falling towards the west, with a pit of 10 meters depth in its middle
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 {
+		v := int16(x + 20*absInt(y-600))
+		if x == 600 && y == 600 {
+			return v - 10
+		}
+		return v
+	})
+	filled := img.FillDepressions()
+	if z := filled.At(image.Point{600, 600}); z <= 599 || z > 600 {
+		t.Error("the pit should be filled up to its spill point at 599, but was", z)
+	}
+	if z := filled.At(image.Point{500, 600}); z != 500 {
+		t.Error("samples without depression should keep their elevation, but was", z)
+	}
+
+	flow, err := img.FlowDirections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := flow.At(image.Point{600, 600}); d != FlowWest {
+		t.Error("the filled pit should drain west, but drained", d)
+	}
+	if d := flow.At(image.Point{600, 300}); d != FlowSouth {
+		t.Error("the valley side should drain south, but drained", d)
+	}
+	if d := flow.At(image.Point{0, 600}); d != NoFlow {
+		t.Error("the valley should leave the tile on its west edge, but drained", d)
+	}
+	if offset := FlowSouthWest.Offset(); offset != (image.Point{-1, 1}) {
+		t.Error("south west should point to -1,1, but was", offset)
+	}
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestStreams(t *testing.T) {
+	// two valleys in the north join the main valley, which falls to the west
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 {
+		main := int16(x + 5*absInt(y-600))
+		if y < 600 {
+			side := int16(1200 - y + 5*minInt(absInt(x-300), absInt(x-900)))
+			if side < main {
+				return side
+			}
+		}
+		return main
+	})
+	img.Data[1000*1201+1000] = VoidValue
+
+	flow, err := img.FlowDirections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accumulation := img.FlowAccumulation(flow)
+	if a := accumulation.At(image.Point{1000, 1000}); !math.IsNaN(float64(a)) {
+		t.Error("voids should not accumulate flow, but had", a)
+	}
+	var total float32
+	for i, d := range flow.Data {
+		if d == NoFlow && !math.IsNaN(float64(accumulation.Data[i])) {
+			total += accumulation.Data[i]
+		}
+	}
+	if total != 1201*1201-1 {
+		t.Error("all samples should drain to an outlet, but the outlets accumulated", total)
+	}
+
+	streams := flow.Streams(accumulation, 20000)
+	orders := map[int]int{}
+	for _, s := range streams {
+		orders[s.Order]++
+		for i := 1; i < len(s.Points); i++ {
+			if math.Abs(s.Points[i].X-s.Points[i-1].X) > 1 || math.Abs(s.Points[i].Y-s.Points[i-1].Y) > 1 {
+				t.Fatal("stream points should be neighbours, but were", s.Points[i-1], s.Points[i])
+			}
+		}
+	}
+	if orders[1] < 2 || orders[2] < 1 {
+		t.Error("the side valleys should join to a stream of order 2, but found", orders)
+	}
+
+	var buf bytes.Buffer
+	if err := img.WriteStreamsGeoJSON(&buf, streams); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			Properties struct {
+				Order int
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil || len(collection.Features) != len(streams) {
+		t.Error("GeoJSON should contain all streams, but was", err, buf.String())
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -28,7 +28,7 @@ func (srtmImg *SRTMImage) HeightCenteredImage(height int16) *image.Gray {
 // The factor determines how many meters are represented by one brightness value.
 // The center height determines the elevation value that corresponds to the brightness value of 128.
 func (srtmImg *SRTMImage) ScaledHeightImage(factor, centerHeight int16) *image.Gray {
-	rect := image.Rect(0, 0, srtmImg.Format.Size(), srtmImg.Format.Size())
+	rect := image.Rect(0, 0, srtmImg.Width(), srtmImg.Height())
 	img := image.NewGray(rect)
 
 	factor32 := int32(factor)
@@ -51,7 +51,7 @@ func (srtmImg *SRTMImage) ScaledHeightImage(factor, centerHeight int16) *image.G
 
 // heightCenteredImage centeres the elevation data to the provided elevation value.
 func heightCenteredImage(srtmImg *SRTMImage, height int16) *image.Gray {
-	rect := image.Rect(0, 0, srtmImg.Format.Size(), srtmImg.Format.Size())
+	rect := image.Rect(0, 0, srtmImg.Width(), srtmImg.Height())
 	img := image.NewGray(rect)
 
 	height32 := int32(height)
@@ -76,7 +76,7 @@ func heightCenteredImage(srtmImg *SRTMImage, height int16) *image.Gray {
 // Please note that some image viewers are not able to display 16 bit images.
 // In many cases, the image will be displayed as a 8 bit image or will be displayed incorrectly.
 func (srtmImg *SRTMImage) FullImage() *image.Gray16 {
-	rect := image.Rect(0, 0, srtmImg.Format.Size(), srtmImg.Format.Size())
+	rect := image.Rect(0, 0, srtmImg.Width(), srtmImg.Height())
 	img := image.NewGray16(rect)
 
 	for i, v := range srtmImg.Data {
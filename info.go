@@ -16,8 +16,7 @@ func (srtmImg *SRTMImage) ElevationVoids() []image.Point {
 	var points []image.Point
 	for i, v := range srtmImg.Data {
 		if v == -32768 {
-			point, _ := IndexToCoordinates(i, srtmImg.Format)
-			points = append(points, point)
+			points = append(points, image.Point{i % srtmImg.Width(), i / srtmImg.Width()})
 		}
 	}
 	return points
@@ -61,11 +60,17 @@ func (srtmImg *SRTMImage) ElevationMean() int16 {
 
 // ElevationAt returns the elevation value at the given coordinates.
 func (srtmImg *SRTMImage) ElevationAt(point image.Point) (int16, error) {
-	index, err := CoordinatesToIndex(point, srtmImg.Format)
-	if err != nil {
-		return -1, err
+	if !srtmImg.IsMosaic() {
+		index, err := CoordinatesToIndex(point, srtmImg.Format)
+		if err != nil {
+			return -1, err
+		}
+		return srtmImg.Data[index], nil
+	}
+	if !point.In(image.Rect(0, 0, srtmImg.Width(), srtmImg.Height())) {
+		return -1, fmt.Errorf("%w: mosaic of %vx%v %v tiles, point: %v", ErrPointOutOfBounds, srtmImg.Columns, srtmImg.Rows, srtmImg.Format, point)
 	}
-	return srtmImg.Data[index], nil
+	return srtmImg.Data[point.Y*srtmImg.Width()+point.X], nil
 }
 
 // ElevationPercentile returns the nearest-ranked percentile of the elevation values.
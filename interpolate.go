@@ -0,0 +1,187 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+)
+
+var ErrLatLonOutOfBounds = errors.New("latitude/longitude out of bounds for SRTM tile")
+var ErrVoid = errors.New("elevation data void")
+
+// VoidValue is the value used by SRTM to flag samples without elevation data.
+const VoidValue int16 = -32768
+
+// Interpolation selects how elevations between sample centers are computed.
+type Interpolation int
+
+const (
+	NearestNeighbor = Interpolation(iota)
+	Bilinear
+	Bicubic
+)
+
+func (i Interpolation) String() string {
+	switch i {
+	case NearestNeighbor:
+		return "nearest"
+	case Bilinear:
+		return "bilinear"
+	case Bicubic:
+		return "bicubic"
+	}
+	return "invalid interpolation"
+}
+
+// LatLonToPoint converts WGS84 latitude/longitude into fractional sample coordinates of the image.
+// Integer values refer to the geometric center of a sample, as per the SRTM documentation.
+// Row 0 is the northern edge of the image, so the y axis points south.
+// The coordinates are NaN if the tile of the image is not known, see TileKnown.
+func (srtmImg *SRTMImage) LatLonToPoint(lat, lon float64) (x, y float64) {
+	if !srtmImg.TileKnown {
+		return math.NaN(), math.NaN()
+	}
+	steps := float64(srtmImg.Format.Size() - 1)
+	x = (lon - float64(srtmImg.Tile.Lon)) * steps
+	y = (float64(srtmImg.Tile.Lat+atLeastOne(srtmImg.Rows)) - lat) * steps
+	return
+}
+
+// PointToLatLon converts sample coordinates to the WGS84 latitude/longitude of the sample center.
+// The latitude/longitude is NaN if the tile of the image is not known.
+func (srtmImg *SRTMImage) PointToLatLon(point image.Point) (lat, lon float64) {
+	return srtmImg.FractionalPointToLatLon(float64(point.X), float64(point.Y))
+}
+
+// FractionalPointToLatLon converts fractional sample coordinates, as returned by LatLonToPoint,
+// to WGS84 latitude/longitude. The latitude/longitude is NaN if the tile of the image is not known.
+func (srtmImg *SRTMImage) FractionalPointToLatLon(x, y float64) (lat, lon float64) {
+	if !srtmImg.TileKnown {
+		return math.NaN(), math.NaN()
+	}
+	steps := float64(srtmImg.Format.Size() - 1)
+	lat = float64(srtmImg.Tile.Lat+atLeastOne(srtmImg.Rows)) - y/steps
+	lon = float64(srtmImg.Tile.Lon) + x/steps
+	return
+}
+
+// ElevationAtLatLon returns the elevation at the given WGS84 latitude/longitude,
+// which must lie inside the tile of the image. Images with an unknown tile return ErrUnknownTile.
+//
+// With NearestNeighbor the closest sample is returned and ErrVoid if it is a void.
+// Bilinear ignores void neighbours and weights the remaining samples accordingly,
+// it only returns ErrVoid if all four neighbours are voids.
+// Bicubic falls back to Bilinear if any of its sixteen neighbours is a void.
+func (srtmImg *SRTMImage) ElevationAtLatLon(lat, lon float64, interpolation Interpolation) (float64, error) {
+	x, y, err := srtmImg.latLonToPointInBounds(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+	return interpolate(srtmImg.clampedAt, x, y, interpolation)
+}
+
+// latLonToPointInBounds is LatLonToPoint, but returns ErrLatLonOutOfBounds
+// if the coordinates lie outside of the tile and ErrUnknownTile if the tile is not known.
+// It only depends on the format and tile, but not the data of the image.
+func (srtmImg *SRTMImage) latLonToPointInBounds(lat, lon float64) (x, y float64, err error) {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return 0, 0, err
+	}
+	x, y = srtmImg.LatLonToPoint(lat, lon)
+	maxX, maxY := float64(srtmImg.Width()-1), float64(srtmImg.Height()-1)
+	// allow for floating point noise at the tile edges
+	const epsilon = 1e-9
+	if math.IsNaN(x) || math.IsNaN(y) || x < -epsilon || y < -epsilon || x > maxX+epsilon || y > maxY+epsilon {
+		return 0, 0, fmt.Errorf("%w: %v, lat: %v, lon: %v", ErrLatLonOutOfBounds, srtmImg.Tile, lat, lon)
+	}
+	return x, y, nil
+}
+
+// clampedAt returns the sample at x,y, clamping the coordinates to the image bounds.
+func (srtmImg *SRTMImage) clampedAt(x, y int) int16 {
+	width := srtmImg.Width()
+	x = clamp(x, 0, width-1)
+	y = clamp(y, 0, srtmImg.Height()-1)
+	return srtmImg.Data[y*width+x]
+}
+
+// interpolate computes the elevation at the fractional sample coordinates x,y.
+// The sample function must handle coordinates outside of the data itself, e.g. by clamping.
+func interpolate(sample func(x, y int) int16, x, y float64, interpolation Interpolation) (float64, error) {
+	switch interpolation {
+	case NearestNeighbor:
+		v := sample(int(math.Floor(x+0.5)), int(math.Floor(y+0.5)))
+		if v == VoidValue {
+			return 0, ErrVoid
+		}
+		return float64(v), nil
+	case Bilinear:
+		return bilinear(sample, x, y)
+	case Bicubic:
+		return bicubic(sample, x, y)
+	}
+	return 0, fmt.Errorf("unknown interpolation: %d", interpolation)
+}
+
+func bilinear(sample func(x, y int) int16, x, y float64) (float64, error) {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	dx, dy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	weights := [4]float64{(1 - dx) * (1 - dy), dx * (1 - dy), (1 - dx) * dy, dx * dy}
+	values := [4]int16{sample(ix, iy), sample(ix+1, iy), sample(ix, iy+1), sample(ix+1, iy+1)}
+
+	var sum, weightSum float64
+	for i, v := range values {
+		// skip voids and renormalize with the remaining weights
+		if v == VoidValue || weights[i] == 0 {
+			continue
+		}
+		sum += weights[i] * float64(v)
+		weightSum += weights[i]
+	}
+	if weightSum == 0 {
+		return 0, ErrVoid
+	}
+	return sum / weightSum, nil
+}
+
+func bicubic(sample func(x, y int) int16, x, y float64) (float64, error) {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	dx, dy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	var rows [4]float64
+	for j := 0; j < 4; j++ {
+		var p [4]float64
+		for i := 0; i < 4; i++ {
+			v := sample(ix+i-1, iy+j-1)
+			if v == VoidValue {
+				return bilinear(sample, x, y)
+			}
+			p[i] = float64(v)
+		}
+		rows[j] = cubicHermite(p, dx)
+	}
+	return cubicHermite(rows, dy), nil
+}
+
+// cubicHermite interpolates between p[1] and p[2] using a Catmull-Rom spline.
+func cubicHermite(p [4]float64, t float64) float64 {
+	a := -p[0]/2 + 3*p[1]/2 - 3*p[2]/2 + p[3]/2
+	b := p[0] - 5*p[1]/2 + 2*p[2] - p[3]/2
+	c := -p[0]/2 + p[2]/2
+	d := p[1]
+	return ((a*t+b)*t+c)*t + d
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package srtm
+
+import (
+	"errors"
+	"image"
+	"io"
+	"math"
+	"testing"
+)
+
+// newTestImage returns a SRTM3 image of the given tile, filled by the elevation function.
+func newTestImage(tile Tile, elevation func(x, y int) int16) *SRTMImage {
+	size := SRTM3Format.Size()
+	data := make([]int16, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			data[y*size+x] = elevation(x, y)
+		}
+	}
+	return &SRTMImage{Data: data, Format: SRTM3Format, Tile: tile, TileKnown: true}
+}
+
+func TestLatLonToPoint(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return 0 })
+	x, y := img.LatLonToPoint(49, 12)
+	if x != 0 || y != 0 {
+		t.Error("LatLonToPoint(49,12) should return (0,0), but returned", x, y)
+	}
+	x, y = img.LatLonToPoint(48, 13)
+	if x != 1200 || y != 1200 {
+		t.Error("LatLonToPoint(48,13) should return (1200,1200), but returned", x, y)
+	}
+	lat, lon := img.PointToLatLon(image.Point{600, 600})
+	if lat != 48.5 || lon != 12.5 {
+		t.Error("PointToLatLon(600,600) should return (48.5,12.5), but returned", lat, lon)
+	}
+}
+
+func TestUnknownTile(t *testing.T) {
+	img := newTestImage(Tile{}, func(x, y int) int16 { return int16(x) })
+	img.TileKnown = false
+
+	if x, y := img.LatLonToPoint(0.5, 0.5); !math.IsNaN(x) || !math.IsNaN(y) {
+		t.Error("LatLonToPoint of an unknown tile should return NaN, but returned", x, y)
+	}
+	if lat, lon := img.PointToLatLon(image.Point{600, 600}); !math.IsNaN(lat) || !math.IsNaN(lon) {
+		t.Error("PointToLatLon of an unknown tile should return NaN, but returned", lat, lon)
+	}
+	check := func(name string, err error) {
+		t.Helper()
+		if !errors.Is(err, ErrUnknownTile) {
+			t.Error(name, "of an unknown tile should return an ErrUnknownTile error, but returned", err)
+		}
+	}
+	_, err := img.ElevationAtLatLon(0.5, 0.5, Bilinear)
+	check("ElevationAtLatLon", err)
+	_, err = img.Slope(Horn, SlopeDegrees)
+	check("Slope", err)
+	_, err = img.Aspect(Horn)
+	check("Aspect", err)
+	_, err = img.HillshadeImage(DefaultHillshadeOptions)
+	check("HillshadeImage", err)
+	_, err = img.FlowDirections()
+	check("FlowDirections", err)
+	_, err = img.Watershed(&FlowDirections{}, &Float32Grid{}, 0.5, 0.5, 0)
+	check("Watershed", err)
+	check("WriteContoursGeoJSON", img.WriteContoursGeoJSON(io.Discard, []Contour{{Points: []PointF{{1, 1}}}}))
+	check("WriteStreamsGeoJSON", img.WriteStreamsGeoJSON(io.Discard, []Stream{{Points: []PointF{{1, 1}}}}))
+	check("WriteWatershedGeoJSON", img.WriteWatershedGeoJSON(io.Discard, &Watershed{}))
+
+	// resampling a source needs the tiles, copying the same grid does not
+	img.Data[600*1201+600] = VoidValue
+	source := newTestImage(Tile{}, func(x, y int) int16 { return 42 })
+	source.TileKnown = false
+	filled, _, err := img.FillVoids(FillOptions{Source: source})
+	if err != nil || filled.Data[600*1201+600] != 42 {
+		t.Error("FillVoids should copy a source of the same grid, but returned", err)
+	}
+	source.TileKnown = true
+	_, _, err = img.FillVoids(FillOptions{Source: source})
+	check("FillVoids from a resampled source", err)
+}
+
+func TestElevationAtLatLon(t *testing.T) {
+	// elevation rises by one meter per sample to the east and by two meters per sample to the south
+	img := newTestImage(Tile{-33, -71}, func(x, y int) int16 { return int16(x + 2*y) })
+
+	for _, interpolation := range []Interpolation{NearestNeighbor, Bilinear, Bicubic} {
+		v, err := img.ElevationAtLatLon(-33, -71, interpolation)
+		if err != nil || v != 2400 {
+			t.Errorf("%v: ElevationAtLatLon(-33,-71) should return 2400, but returned %v %v", interpolation, v, err)
+		}
+		v, err = img.ElevationAtLatLon(-32, -71, interpolation)
+		if err != nil || v != 0 {
+			t.Errorf("%v: ElevationAtLatLon(-32,-71) should return 0, but returned %v %v", interpolation, v, err)
+		}
+	}
+
+	lat, lon := img.PointToLatLon(image.Point{10, 10})
+	lon += 0.5 / 1200
+	v, _ := img.ElevationAtLatLon(lat, lon, Bilinear)
+	if math.Abs(v-30.5) > 1e-6 {
+		t.Error("Bilinear between samples should return 30.5, but returned", v)
+	}
+	v, _ = img.ElevationAtLatLon(lat, lon, Bicubic)
+	if math.Abs(v-30.5) > 1e-6 {
+		t.Error("Bicubic on a plane should return 30.5, but returned", v)
+	}
+
+	_, err := img.ElevationAtLatLon(-31.5, -71, Bilinear)
+	if errors.Is(err, ErrLatLonOutOfBounds) == false {
+		t.Error("ElevationAtLatLon outside the tile should return ErrLatLonOutOfBounds, but returned", err)
+	}
+}
+
+func TestElevationAtLatLonVoid(t *testing.T) {
+	img := newTestImage(Tile{0, 0}, func(x, y int) int16 {
+		if x == 10 && y == 10 {
+			return VoidValue
+		}
+		return 100
+	})
+
+	lat, lon := img.PointToLatLon(image.Point{10, 10})
+	_, err := img.ElevationAtLatLon(lat, lon, NearestNeighbor)
+	if errors.Is(err, ErrVoid) == false {
+		t.Error("NearestNeighbor on a void should return ErrVoid, but returned", err)
+	}
+	lon += 0.25 / 1200
+	v, err := img.ElevationAtLatLon(lat, lon, Bilinear)
+	if err != nil || v != 100 {
+		t.Error("Bilinear next to a void should ignore the void, but returned", v, err)
+	}
+	v, err = img.ElevationAtLatLon(lat, lon, Bicubic)
+	if err != nil || v != 100 {
+		t.Error("Bicubic next to a void should fall back to bilinear, but returned", v, err)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package srtm
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// mappedFile is a read-only memory-mapped file.
+type mappedFile struct {
+	data []byte
+}
+
+// mapFile memory-maps the file and closes it, as the mapping stays valid without it.
+// The file is closed in any case, also if the mapping fails.
+func mapFile(f *os.File, size int64) (readerAtCloser, error) {
+	defer f.Close()
+	if size == 0 {
+		return &mappedFile{}, nil
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &mappedFile{data}, nil
+}
+
+func (m *mappedFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *mappedFile) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return syscall.Munmap(data)
+}
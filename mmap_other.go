@@ -0,0 +1,11 @@
+//go:build !linux
+
+package srtm
+
+import "os"
+
+// mapFile returns the file itself on platforms without memory-mapping support.
+// Like on Linux, the returned reader owns the file and closing it closes the file.
+func mapFile(f *os.File, size int64) (readerAtCloser, error) {
+	return f, nil
+}
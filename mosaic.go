@@ -0,0 +1,180 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotSingleTile = errors.New("operation requires a single SRTM tile, not a mosaic")
+var ErrEmptyMosaic = errors.New("mosaic without tiles")
+var ErrMosaicTooLarge = errors.New("mosaic exceeds MaxMosaicSamples")
+
+// MaxMosaicSamples limits the number of samples of a mosaic to a square of 4x4 SRTM1 tiles,
+// which takes about 415 MB, or 12x12 SRTM3 tiles.
+const MaxMosaicSamples = (4*(SRTM1Size-1) + 1) * (4*(SRTM1Size-1) + 1)
+
+// checkMosaicSize returns ErrMosaicTooLarge if a mosaic of the given tiles exceeds MaxMosaicSamples.
+func checkMosaicSize(format SRTMFormat, columns, rows int) error {
+	steps := float64(format.Size() - 1)
+	if samples := (float64(columns)*steps + 1) * (float64(rows)*steps + 1); samples > MaxMosaicSamples {
+		return fmt.Errorf("%w: %v mosaic of %vx%v tiles", ErrMosaicTooLarge, format, columns, rows)
+	}
+	return nil
+}
+
+// Mosaic merges the images of adjacent tiles into a single image, which spans the bounding box
+// of their tiles and can be used like the image of a single tile. The rows and columns shared by
+// neighbouring tiles are only stored once, preferring samples which are not voids.
+// Tiles missing inside of the bounding box are filled with voids.
+//
+// The mosaic has the highest resolution of the images, images of a lower resolution are
+// resampled bilinearly. The images must be single tiles with known tiles.
+// Bounding boxes exceeding MaxMosaicSamples return ErrMosaicTooLarge.
+func Mosaic(images ...*SRTMImage) (*SRTMImage, error) {
+	if len(images) == 0 {
+		return nil, ErrEmptyMosaic
+	}
+	south, west := images[0].Tile.Lat, images[0].Tile.Lon
+	north, east := south, west
+	format := images[0].Format
+	for _, img := range images {
+		if img.IsMosaic() {
+			return nil, fmt.Errorf("%w: %v", ErrNotSingleTile, img.Tile)
+		}
+		if err := img.checkTileKnown(); err != nil {
+			return nil, err
+		}
+		if err := img.checkDataLength(); err != nil {
+			return nil, fmt.Errorf("%v: %w", img.Tile, err)
+		}
+		if img.Tile.Lat < south {
+			south = img.Tile.Lat
+		} else if img.Tile.Lat > north {
+			north = img.Tile.Lat
+		}
+		if img.Tile.Lon < west {
+			west = img.Tile.Lon
+		} else if img.Tile.Lon > east {
+			east = img.Tile.Lon
+		}
+		if img.Format.Size() > format.Size() {
+			format = img.Format
+		}
+	}
+
+	if err := checkMosaicSize(format, east-west+1, north-south+1); err != nil {
+		return nil, err
+	}
+	mosaic := newMosaic(format, Tile{south, west}, east-west+1, north-south+1)
+	for _, img := range images {
+		mosaic.paste(img)
+	}
+	return mosaic, nil
+}
+
+// Mosaic merges the tiles of the dataset from the south-western tile on into a single image
+// of the given number of tile columns and rows, see Mosaic. Ocean tiles are at sea level,
+// other tiles missing from the dataset are voids. The mosaic must not cross the antimeridian
+// and must not exceed MaxMosaicSamples.
+func (d *Dataset) Mosaic(southWest Tile, columns, rows int) (*SRTMImage, error) {
+	northEast := Tile{southWest.Lat + rows - 1, southWest.Lon + columns - 1}
+	if columns < 1 || rows < 1 || !southWest.IsValid() || !northEast.IsValid() {
+		return nil, fmt.Errorf("%w: mosaic of %vx%v tiles from %v", ErrInvalidTile, columns, rows, southWest)
+	}
+
+	format := SRTMFormat(-1)
+	var ocean []Tile
+	for lat := southWest.Lat; lat <= northEast.Lat; lat++ {
+		for lon := southWest.Lon; lon <= northEast.Lon; lon++ {
+			tile := Tile{lat, lon}
+			if file, ok := d.files[tile]; ok {
+				if file.format.Size() > format.Size() {
+					format = file.format
+				}
+			} else if d.IsOcean != nil && d.IsOcean(tile) {
+				ocean = append(ocean, tile)
+			}
+		}
+	}
+	if format < 0 {
+		if len(ocean) == 0 {
+			return nil, fmt.Errorf("%w: %vx%v tiles from %v", ErrEmptyMosaic, columns, rows, southWest)
+		}
+		format = SRTM3Format
+	}
+	if err := checkMosaicSize(format, columns, rows); err != nil {
+		return nil, err
+	}
+
+	mosaic := newMosaic(format, southWest, columns, rows)
+	for lat := southWest.Lat; lat <= northEast.Lat; lat++ {
+		for lon := southWest.Lon; lon <= northEast.Lon; lon++ {
+			if !d.HasTile(Tile{lat, lon}) {
+				continue
+			}
+			img, err := d.Image(Tile{lat, lon})
+			if err != nil {
+				return nil, err
+			}
+			mosaic.paste(img)
+		}
+	}
+	for _, tile := range ocean {
+		mosaic.fillTile(tile, func(lat, lon float64) (int16, bool) { return 0, true })
+	}
+	return mosaic, nil
+}
+
+// newMosaic returns a mosaic of voids.
+func newMosaic(format SRTMFormat, southWest Tile, columns, rows int) *SRTMImage {
+	mosaic := &SRTMImage{Format: format, Tile: southWest, TileKnown: true, Columns: columns, Rows: rows}
+	mosaic.Data = make([]int16, mosaic.Width()*mosaic.Height())
+	for i := range mosaic.Data {
+		mosaic.Data[i] = VoidValue
+	}
+	return mosaic
+}
+
+// paste copies the samples of the tile into the mosaic, resampling them if the formats differ.
+func (mosaic *SRTMImage) paste(img *SRTMImage) {
+	if img.Format != mosaic.Format {
+		mosaic.fillTile(img.Tile, func(lat, lon float64) (int16, bool) {
+			v, err := img.ElevationAtLatLon(lat, lon, Bilinear)
+			return roundElevation(v), err == nil
+		})
+		return
+	}
+
+	last := mosaic.Format.Size() - 1
+	width := mosaic.Width()
+	x0 := (img.Tile.Lon - mosaic.Tile.Lon) * last
+	y0 := (mosaic.Tile.Lat + mosaic.Rows - 1 - img.Tile.Lat) * last
+	for y := 0; y <= last; y++ {
+		for x := 0; x <= last; x++ {
+			v := img.Data[y*(last+1)+x]
+			i := (y0+y)*width + x0 + x
+			if v != VoidValue || mosaic.Data[i] == VoidValue {
+				mosaic.Data[i] = v
+			}
+		}
+	}
+}
+
+// fillTile sets the voids inside of the tile to the values returned by sample, if it reports ok.
+func (mosaic *SRTMImage) fillTile(tile Tile, sample func(lat, lon float64) (int16, bool)) {
+	last := mosaic.Format.Size() - 1
+	width := mosaic.Width()
+	x0 := (tile.Lon - mosaic.Tile.Lon) * last
+	y0 := (mosaic.Tile.Lat + mosaic.Rows - 1 - tile.Lat) * last
+	for y := y0; y <= y0+last; y++ {
+		for x := x0; x <= x0+last; x++ {
+			if mosaic.Data[y*width+x] != VoidValue {
+				continue
+			}
+			lat, lon := mosaic.FractionalPointToLatLon(float64(x), float64(y))
+			if v, ok := sample(lat, lon); ok {
+				mosaic.Data[y*width+x] = v
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestMosaic(t *testing.T) {
+	// three of the four tiles of a 2x2 mosaic, N49E012 is missing
+	sw := newTestImage(Tile{48, 12}, func(x, y int) int16 { return 1 })
+	se := newTestImage(Tile{48, 13}, func(x, y int) int16 { return 2 })
+	nw := newTestImage(Tile{49, 12}, func(x, y int) int16 { return 3 })
+	// a void on the shared edge is taken from the neighbour
+	se.Data[600*1201] = VoidValue
+
+	mosaic, err := Mosaic(se, nw, sw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mosaic.Tile != (Tile{48, 12}) || mosaic.Width() != 2401 || mosaic.Height() != 2401 || len(mosaic.Data) != 2401*2401 {
+		t.Fatal("mosaic should span 2401x2401 samples from N48E012, but was", mosaic.Tile, mosaic.Width(), mosaic.Height())
+	}
+	expected := map[image.Point]int16{
+		{0, 0}: 3, {1199, 1199}: 3, {0, 1201}: 1, {1201, 1201}: 2, {2400, 2400}: 2,
+		{1300, 100}: VoidValue, {1200, 1800}: 1,
+	}
+	for point, v := range expected {
+		if e, err := mosaic.ElevationAt(point); err != nil || e != v {
+			t.Error("mosaic at", point, "should be", v, "but was", e, err)
+		}
+	}
+	if _, err := mosaic.ElevationAt(image.Point{2401, 0}); !errors.Is(err, ErrPointOutOfBounds) {
+		t.Error("ElevationAt outside of the mosaic should return an ErrPointOutOfBounds error, but returned", err)
+	}
+
+	v, err := mosaic.ElevationAtLatLon(48.5, 13.5, Bilinear)
+	if err != nil || v != 2 {
+		t.Error("ElevationAtLatLon in the south-eastern tile should return 2, but returned", v, err)
+	}
+	if x, y := mosaic.LatLonToPoint(48, 14); x != 2400 || y != 2400 {
+		t.Error("the south-eastern corner should be at 2400,2400, but was", x, y)
+	}
+	if _, err := mosaic.ElevationAtLatLon(47.5, 13, Bilinear); !errors.Is(err, ErrLatLonOutOfBounds) {
+		t.Error("ElevationAtLatLon outside of the mosaic should return an ErrLatLonOutOfBounds error, but returned", err)
+	}
+
+	if min, max := mosaic.ElevationMinMax(); min != 1 || max != 3 {
+		t.Error("mosaic should range from 1 to 3, but was", min, max)
+	}
+	hillshade, err := mosaic.HillshadeImage(DefaultHillshadeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := hillshade.Bounds(); bounds != image.Rect(0, 0, 2401, 2401) {
+		t.Error("images of the mosaic should span all samples, but were", bounds)
+	}
+	if len(mosaic.ElevationVoids()) != 1200*1200 {
+		t.Error("the missing tile should be voids, but were", len(mosaic.ElevationVoids()))
+	}
+	if err := mosaic.Encode(&bytes.Buffer{}); !errors.Is(err, ErrNotSingleTile) {
+		t.Error("encoding a mosaic as HGT should return an ErrNotSingleTile error, but returned", err)
+	}
+	if _, err := Mosaic(); !errors.Is(err, ErrEmptyMosaic) {
+		t.Error("Mosaic without images should return an ErrEmptyMosaic error, but returned", err)
+	}
+}
+
+func TestDatasetMosaic(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x) }))
+	// a SRTM1 tile raises the resolution of the mosaic
+	srtm1 := &SRTMImage{Data: make([]int16, 3601*3601), Format: SRTM1Format, Tile: Tile{48, 13}, TileKnown: true}
+	for i := range srtm1.Data {
+		srtm1.Data[i] = int16(1200 + i%3601/3)
+	}
+	writeTestTile(t, dir, srtm1)
+
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataset.IsOcean = func(tile Tile) bool { return tile.Lon == 14 }
+
+	mosaic, err := dataset.Mosaic(Tile{48, 12}, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mosaic.Format != SRTM1Format || mosaic.Width() != 3*3600+1 || mosaic.Height() != 3601 {
+		t.Fatal("mosaic should be SRTM1 with 10801x3601 samples, but was", mosaic.Format, mosaic.Width(), mosaic.Height())
+	}
+	for _, lon := range []float64{12.25, 12.5, 13, 13.25, 13.5} {
+		v, err := mosaic.ElevationAtLatLon(48.5, lon, Bilinear)
+		if expected := (lon - 12) * 1200; err != nil || math.Abs(v-expected) > 0.5 {
+			t.Error("mosaic at longitude", lon, "should be", expected, "but was", v, err)
+		}
+	}
+	if v, err := mosaic.ElevationAtLatLon(48.5, 14.5, NearestNeighbor); err != nil || v != 0 {
+		t.Error("the ocean tile should be at sea level, but was", v, err)
+	}
+
+	if _, err := dataset.Mosaic(Tile{48, 179}, 2, 1); !errors.Is(err, ErrInvalidTile) {
+		t.Error("a mosaic across the antimeridian should return an ErrInvalidTile error, but returned", err)
+	}
+	if _, err := dataset.Mosaic(Tile{10, 10}, 1, 1); !errors.Is(err, ErrEmptyMosaic) {
+		t.Error("a mosaic without tiles should return an ErrEmptyMosaic error, but returned", err)
+	}
+	if _, err := dataset.Mosaic(Tile{45, 12}, 5, 4); !errors.Is(err, ErrMosaicTooLarge) {
+		t.Error("a SRTM1 mosaic of 5x4 tiles should return an ErrMosaicTooLarge error, but returned", err)
+	}
+}
+
+func TestMosaicTooLarge(t *testing.T) {
+	// the bounding box of two distant tiles is limited, not the tiles themselves
+	sw := newTestImage(Tile{0, 0}, func(x, y int) int16 { return 1 })
+	ne := newTestImage(Tile{50, 50}, func(x, y int) int16 { return 2 })
+	if _, err := Mosaic(sw, ne); !errors.Is(err, ErrMosaicTooLarge) {
+		t.Error("a mosaic of 51x51 tiles should return an ErrMosaicTooLarge error, but returned", err)
+	}
+	ne.Tile = Tile{11, 11}
+	if _, err := Mosaic(sw, ne); err != nil {
+		t.Error("a SRTM3 mosaic of 12x12 tiles should be allowed, but returned", err)
+	}
+}
@@ -0,0 +1,253 @@
+package srtm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPalette = errors.New("invalid color relief palette")
+
+// ColorStop assigns a color to an elevation. Colors between stops are interpolated linearly.
+type ColorStop struct {
+	// Elevation in meters, or in percent of the elevation range of the image if Percent is set.
+	Elevation float64
+	Percent   bool
+	Color     color.NRGBA
+}
+
+// Palette maps elevations to colors for ColorReliefImage.
+// Colors are not alpha-premultiplied, as in GDAL color-relief files.
+// Elevations below the first or above the last stop use the color of that stop.
+type Palette struct {
+	Stops []ColorStop
+	// Void is the color of data voids.
+	Void color.NRGBA
+	// BelowSeaLevel is the color of all elevations below 0 meters, if set.
+	// Otherwise these elevations are colored by the stops as well.
+	BelowSeaLevel *color.NRGBA
+}
+
+// namedColors are the color names understood by GDAL color-relief files.
+var namedColors = map[string]color.NRGBA{
+	"white":   {255, 255, 255, 255},
+	"black":   {0, 0, 0, 255},
+	"red":     {255, 0, 0, 255},
+	"green":   {0, 255, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+	"magenta": {255, 0, 255, 255},
+	"fuchsia": {255, 0, 255, 255},
+	"cyan":    {0, 255, 255, 255},
+	"aqua":    {0, 255, 255, 255},
+	"grey":    {190, 190, 190, 255},
+	"gray":    {190, 190, 190, 255},
+	"orange":  {255, 165, 0, 255},
+	"none":    {0, 0, 0, 0},
+}
+
+// ParsePalette reads a palette in the text format of GDAL's gdaldem color-relief.
+// Each line holds an elevation followed by red, green, blue and an optional alpha value,
+// or a color name instead of the values. Elevations may be given in percent, e.g. 50%,
+// and the elevation nv sets the color of voids. Values may be separated by spaces,
+// tabs, commas or colons. Empty lines and lines starting with # are ignored.
+func ParsePalette(r io.Reader) (*Palette, error) {
+	p := &Palette{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.FieldsFunc(scanner.Text(), func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ',' || r == ':'
+		})
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		c, err := parseColor(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %v: %v", ErrInvalidPalette, line, err)
+		}
+		if strings.EqualFold(fields[0], "nv") {
+			p.Void = c
+			continue
+		}
+
+		stop := ColorStop{Color: c}
+		value := fields[0]
+		if strings.HasSuffix(value, "%") {
+			stop.Percent = true
+			value = strings.TrimSuffix(value, "%")
+		}
+		stop.Elevation, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %v: invalid elevation %q", ErrInvalidPalette, line, fields[0])
+		}
+		p.Stops = append(p.Stops, stop)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.Stops) == 0 {
+		return nil, fmt.Errorf("%w: no color stops", ErrInvalidPalette)
+	}
+	return p, nil
+}
+
+func parseColor(fields []string) (color.NRGBA, error) {
+	if len(fields) == 1 {
+		c, ok := namedColors[strings.ToLower(fields[0])]
+		if !ok {
+			return color.NRGBA{}, fmt.Errorf("unknown color %q", fields[0])
+		}
+		return c, nil
+	}
+	if len(fields) != 3 && len(fields) != 4 {
+		return color.NRGBA{}, fmt.Errorf("expected 3 or 4 color components, but got %v", len(fields))
+	}
+	components := [4]uint8{0, 0, 0, 255}
+	for i, field := range fields {
+		v, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("invalid color component %q", field)
+		}
+		components[i] = uint8(v)
+	}
+	return color.NRGBA{components[0], components[1], components[2], components[3]}, nil
+}
+
+// LoadPalette reads a GDAL color-relief palette from the file with the given name.
+func LoadPalette(name string) (*Palette, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := ParsePalette(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return p, nil
+}
+
+// BuiltinPaletteNames lists the names accepted by BuiltinPalette.
+var BuiltinPaletteNames = []string{"hypsometric", "terrain", "grayscale"}
+
+// BuiltinPalette returns a copy of one of the palettes shipped with the library:
+//   - hypsometric: the classic atlas tints from green lowlands over brown mountains to white peaks,
+//     with blue below sea level
+//   - terrain: a muted palette for elevations up to the Alps, suitable as a base map
+//   - grayscale: black to white over the elevation range of the image
+func BuiltinPalette(name string) (*Palette, error) {
+	sea := color.NRGBA{90, 150, 210, 255}
+	switch name {
+	case "hypsometric":
+		return &Palette{
+			Stops: []ColorStop{
+				{Elevation: 0, Color: color.NRGBA{70, 140, 70, 255}},
+				{Elevation: 200, Color: color.NRGBA{130, 180, 90, 255}},
+				{Elevation: 500, Color: color.NRGBA{230, 220, 130, 255}},
+				{Elevation: 1000, Color: color.NRGBA{200, 150, 80, 255}},
+				{Elevation: 2000, Color: color.NRGBA{150, 90, 50, 255}},
+				{Elevation: 3500, Color: color.NRGBA{160, 150, 145, 255}},
+				{Elevation: 5000, Color: color.NRGBA{255, 255, 255, 255}},
+			},
+			BelowSeaLevel: &sea,
+		}, nil
+	case "terrain":
+		return &Palette{
+			Stops: []ColorStop{
+				{Elevation: -100, Color: color.NRGBA{60, 110, 170, 255}},
+				{Elevation: 0, Color: color.NRGBA{110, 160, 110, 255}},
+				{Elevation: 400, Color: color.NRGBA{190, 200, 140, 255}},
+				{Elevation: 1200, Color: color.NRGBA{210, 180, 140, 255}},
+				{Elevation: 2500, Color: color.NRGBA{180, 170, 160, 255}},
+				{Elevation: 4000, Color: color.NRGBA{245, 245, 245, 255}},
+			},
+		}, nil
+	case "grayscale":
+		return &Palette{
+			Stops: []ColorStop{
+				{Elevation: 0, Percent: true, Color: color.NRGBA{0, 0, 0, 255}},
+				{Elevation: 100, Percent: true, Color: color.NRGBA{255, 255, 255, 255}},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown builtin palette %q", ErrInvalidPalette, name)
+}
+
+// ColorReliefImage colors the elevation data with the palette.
+// Percentages of the palette refer to the range returned by ElevationMinMax.
+func (srtmImg *SRTMImage) ColorReliefImage(p *Palette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, srtmImg.Width(), srtmImg.Height()))
+	lookup := p.lookupTable(srtmImg)
+	for i, v := range srtmImg.Data {
+		c := lookup[int(v)+32768]
+		img.Pix[i*4] = c.R
+		img.Pix[i*4+1] = c.G
+		img.Pix[i*4+2] = c.B
+		img.Pix[i*4+3] = c.A
+	}
+	return img
+}
+
+// lookupTable returns the premultiplied color of every int16 value, indexed by the value shifted by 32768.
+func (p *Palette) lookupTable(srtmImg *SRTMImage) []color.RGBA {
+	min, max := srtmImg.ElevationMinMax()
+	stops := make([]ColorStop, len(p.Stops))
+	copy(stops, p.Stops)
+	for i := range stops {
+		if stops[i].Percent {
+			stops[i].Elevation = float64(min) + stops[i].Elevation/100*float64(max-min)
+			stops[i].Percent = false
+		}
+	}
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Elevation < stops[j].Elevation })
+
+	lookup := make([]color.RGBA, 65536)
+	for i := range lookup {
+		v := int16(i - 32768)
+		var c color.NRGBA
+		switch {
+		case v == VoidValue:
+			c = p.Void
+		case v < 0 && p.BelowSeaLevel != nil:
+			c = *p.BelowSeaLevel
+		default:
+			c = interpolateStops(stops, float64(v))
+		}
+		// image.RGBA stores alpha-premultiplied colors
+		lookup[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	return lookup
+}
+
+// interpolateStops returns the color of the elevation between the sorted stops.
+func interpolateStops(stops []ColorStop, elevation float64) color.NRGBA {
+	if len(stops) == 0 {
+		return color.NRGBA{}
+	}
+	if elevation <= stops[0].Elevation {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		if elevation > stops[i].Elevation {
+			continue
+		}
+		a, b := stops[i-1], stops[i]
+		t := (elevation - a.Elevation) / (b.Elevation - a.Elevation)
+		mix := func(x, y uint8) uint8 {
+			return uint8(float64(x) + t*(float64(y)-float64(x)) + 0.5)
+		}
+		return color.NRGBA{mix(a.Color.R, b.Color.R), mix(a.Color.G, b.Color.G), mix(a.Color.B, b.Color.B), mix(a.Color.A, b.Color.A)}
+	}
+	return stops[len(stops)-1].Color
+}
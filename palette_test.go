@@ -0,0 +1,93 @@
+package srtm
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+const testPalette = `# GDAL color-relief
+nv 0 0 0 0
+0% 0 0 255
+1000,255,255,255
+500:0:255:0:128
+2000 white
+`
+
+func TestParsePalette(t *testing.T) {
+	p, err := ParsePalette(strings.NewReader(testPalette))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Stops) != 4 {
+		t.Fatal("ParsePalette should return 4 stops, but returned", p.Stops)
+	}
+	if !p.Stops[0].Percent || p.Stops[0].Elevation != 0 || p.Stops[0].Color != (color.NRGBA{0, 0, 255, 255}) {
+		t.Error("first stop should be 0% blue, but was", p.Stops[0])
+	}
+	if p.Stops[2].Elevation != 500 || p.Stops[2].Color != (color.NRGBA{0, 255, 0, 128}) {
+		t.Error("third stop should be 500 translucent green, but was", p.Stops[2])
+	}
+	if p.Stops[3].Color != (color.NRGBA{255, 255, 255, 255}) {
+		t.Error("named color white should be parsed, but was", p.Stops[3])
+	}
+	if p.Void != (color.NRGBA{}) {
+		t.Error("nv should set the void color, but was", p.Void)
+	}
+}
+
+func TestParsePaletteError(t *testing.T) {
+	for _, input := range []string{"", "100 1 2", "abc 1 2 3", "100 300 0 0", "100 purple"} {
+		_, err := ParsePalette(strings.NewReader(input))
+		if errors.Is(err, ErrInvalidPalette) == false {
+			t.Errorf("ParsePalette(%q) should return an ErrInvalidPalette error, but returned %v", input, err)
+		}
+	}
+}
+
+func TestColorReliefImage(t *testing.T) {
+	img := newTestImage(Tile{}, func(x, y int) int16 { return int16(x) })
+	img.Data[1] = VoidValue
+	img.Data[2] = -5
+	sea := color.NRGBA{0, 0, 100, 255}
+	p := &Palette{
+		Stops: []ColorStop{
+			{Elevation: 0, Color: color.NRGBA{0, 0, 0, 255}},
+			{Elevation: 100, Color: color.NRGBA{200, 100, 0, 255}},
+		},
+		Void:          color.NRGBA{255, 0, 0, 255},
+		BelowSeaLevel: &sea,
+	}
+
+	relief := img.ColorReliefImage(p)
+	if c := relief.RGBAAt(50, 0); c != (color.RGBA{100, 50, 0, 255}) {
+		t.Error("elevation 50 should be interpolated halfway, but was", c)
+	}
+	if c := relief.RGBAAt(500, 0); c != (color.RGBA{200, 100, 0, 255}) {
+		t.Error("elevation above the last stop should use its color, but was", c)
+	}
+	if c := relief.RGBAAt(1, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("voids should use the void color, but was", c)
+	}
+	if c := relief.RGBAAt(2, 0); c != (color.RGBA{0, 0, 100, 255}) {
+		t.Error("elevations below sea level should use their color, but was", c)
+	}
+
+	gray, _ := BuiltinPalette("grayscale")
+	relief = img.ColorReliefImage(gray)
+	if c := relief.RGBAAt(1200, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Error("the maximum elevation should be white with the grayscale palette, but was", c)
+	}
+}
+
+func TestBuiltinPalettes(t *testing.T) {
+	for _, name := range BuiltinPaletteNames {
+		if _, err := BuiltinPalette(name); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := BuiltinPalette("unknown"); errors.Is(err, ErrInvalidPalette) == false {
+		t.Error("BuiltinPalette(unknown) should return an ErrInvalidPalette error, but returned", err)
+	}
+}
@@ -0,0 +1,186 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidPath = errors.New("invalid path")
+
+// maxProfileSamples limits the number of samples of a profile.
+const maxProfileSamples = 1000000
+
+// EarthRadius is the mean radius of the earth in meters, used for great-circle distances.
+const EarthRadius = 6371008.8
+
+// LatLon is a WGS84 position in degrees.
+type LatLon struct {
+	Lat, Lon float64
+}
+
+// GreatCircleDistance returns the distance between a and b along the surface of the earth in meters.
+func GreatCircleDistance(a, b LatLon) float64 {
+	return EarthRadius * centralAngle(a, b)
+}
+
+// centralAngle returns the angle between a and b as seen from the center of the earth in radians,
+// using the haversine formula, which is precise for short distances.
+func centralAngle(a, b LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// intermediatePoint returns the point at the fraction f of the great circle from a to b,
+// which spans the central angle delta. The great circle between antipodal points is ambiguous,
+// so delta must be less than π.
+func intermediatePoint(a, b LatLon, delta, f float64) LatLon {
+	if delta == 0 {
+		return a
+	}
+	lat1, lon1 := a.Lat*math.Pi/180, a.Lon*math.Pi/180
+	lat2, lon2 := b.Lat*math.Pi/180, b.Lon*math.Pi/180
+	wa := math.Sin((1-f)*delta) / math.Sin(delta)
+	wb := math.Sin(f*delta) / math.Sin(delta)
+	x := wa*math.Cos(lat1)*math.Cos(lon1) + wb*math.Cos(lat2)*math.Cos(lon2)
+	y := wa*math.Cos(lat1)*math.Sin(lon1) + wb*math.Cos(lat2)*math.Sin(lon2)
+	z := wa*math.Sin(lat1) + wb*math.Sin(lat2)
+	return LatLon{
+		Lat: math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+		Lon: math.Atan2(y, x) * 180 / math.Pi,
+	}
+}
+
+// ProfileOptions configure Dataset.Profile.
+type ProfileOptions struct {
+	// Spacing is the distance between two samples along the path in meters.
+	// A Spacing of 0 samples the vertices of the path only.
+	Spacing float64
+	// Interpolation is used to sample the elevation between the grid points.
+	Interpolation Interpolation
+}
+
+// ProfilePoint is a sample of an elevation profile.
+type ProfilePoint struct {
+	LatLon
+	// Distance from the start of the path in meters.
+	Distance  float64
+	Elevation float64
+	// Void is set if the elevation is unknown because of voids in the data.
+	Void bool
+}
+
+// Profile is the elevation along a path.
+// The summary values only take samples with a known elevation into account.
+type Profile struct {
+	Points []ProfilePoint
+	// Length of the path in meters.
+	Length float64
+	// Ascent and Descent are the sums of all climbs and drops between consecutive samples in meters.
+	Ascent  float64
+	Descent float64
+	// MaxGrade is the steepest climb and MinGrade the steepest drop between consecutive samples
+	// in percent, in the direction of the path. Drops have negative grades.
+	MaxGrade     float64
+	MinGrade     float64
+	MinElevation float64
+	MaxElevation float64
+}
+
+// Profile samples the elevation along the path, which connects the vertices by great circles.
+// Besides every vertex, samples are taken at each multiple of the spacing from the start of the path.
+// Samples on ocean tiles have an elevation of 0, samples on voids are marked as such.
+// All other errors, e.g. missing tiles, abort the profile.
+// Paths with antipodal consecutive vertices or more than a million samples return ErrInvalidPath.
+func (d *Dataset) Profile(path []LatLon, options ProfileOptions) (*Profile, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: no vertices", ErrInvalidPath)
+	}
+	if options.Spacing < 0 || math.IsNaN(options.Spacing) || math.IsInf(options.Spacing, 0) {
+		return nil, fmt.Errorf("%w: spacing %v", ErrInvalidPath, options.Spacing)
+	}
+
+	deltas := make([]float64, len(path))
+	samples := float64(len(path))
+	for i := 1; i < len(path); i++ {
+		deltas[i] = centralAngle(path[i-1], path[i])
+		if math.Pi-deltas[i] < 1e-9 {
+			return nil, fmt.Errorf("%w: vertices %v and %v are antipodal", ErrInvalidPath, path[i-1], path[i])
+		}
+		if options.Spacing > 0 {
+			samples += EarthRadius * deltas[i] / options.Spacing
+		}
+	}
+	if samples > maxProfileSamples {
+		return nil, fmt.Errorf("%w: %.0f samples exceed the limit of %v, increase the spacing", ErrInvalidPath, samples, maxProfileSamples)
+	}
+
+	var points []ProfilePoint
+	add := func(p LatLon, distance float64) error {
+		elevation, err := d.ElevationAt(p.Lat, p.Lon, options.Interpolation)
+		void := false
+		switch {
+		case errors.Is(err, ErrVoid):
+			void = true
+		case err != nil && !errors.Is(err, ErrOcean):
+			return err
+		}
+		points = append(points, ProfilePoint{LatLon: p, Distance: distance, Elevation: elevation, Void: void})
+		return nil
+	}
+
+	if err := add(path[0], 0); err != nil {
+		return nil, err
+	}
+	distance := 0.0
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		delta := deltas[i]
+		length := EarthRadius * delta
+		if options.Spacing > 0 {
+			// the multiples of the spacing after the start of the segment, computed
+			// from their index to avoid accumulating rounding errors along the path
+			for k := math.Floor(distance/options.Spacing) + 1; k*options.Spacing < distance+length; k++ {
+				next := k * options.Spacing
+				if err := add(intermediatePoint(a, b, delta, (next-distance)/length), next); err != nil {
+					return nil, err
+				}
+			}
+		}
+		distance += length
+		if err := add(b, distance); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := &Profile{Points: points, Length: distance}
+	var previous *ProfilePoint
+	for i := range points {
+		p := &points[i]
+		if p.Void {
+			continue
+		}
+		if previous == nil {
+			profile.MinElevation, profile.MaxElevation = p.Elevation, p.Elevation
+		} else {
+			rise := p.Elevation - previous.Elevation
+			if rise > 0 {
+				profile.Ascent += rise
+			} else {
+				profile.Descent -= rise
+			}
+			if run := p.Distance - previous.Distance; run > 0 {
+				grade := rise / run * 100
+				profile.MaxGrade = math.Max(profile.MaxGrade, grade)
+				profile.MinGrade = math.Min(profile.MinGrade, grade)
+			}
+			profile.MinElevation = math.Min(profile.MinElevation, p.Elevation)
+			profile.MaxElevation = math.Max(profile.MaxElevation, p.Elevation)
+		}
+		previous = p
+	}
+	return profile, nil
+}
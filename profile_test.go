@@ -0,0 +1,92 @@
+package srtm
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGreatCircleDistance(t *testing.T) {
+	// one degree along the equator
+	d := GreatCircleDistance(LatLon{0, 0}, LatLon{0, 1})
+	if math.Abs(d-EarthRadius*math.Pi/180) > 1e-6 {
+		t.Error("GreatCircleDistance of one degree along the equator should be", EarthRadius*math.Pi/180, "but was", d)
+	}
+	p := intermediatePoint(LatLon{0, 0}, LatLon{0, 90}, math.Pi/2, 0.5)
+	if math.Abs(p.Lat) > 1e-9 || math.Abs(p.Lon-45) > 1e-9 {
+		t.Error("intermediatePoint halfway along the equator should be 0/45, but was", p)
+	}
+}
+
+func TestDatasetProfile(t *testing.T) {
+	dir := t.TempDir()
+	// a ridge rising towards the east until the middle of the tile and falling again
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(600 - math.Abs(float64(x-600))) }))
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := []LatLon{{48.5, 12.25}, {48.5, 12.5}, {48.5, 12.75}}
+	profile, err := dataset.Profile(path, ProfileOptions{Spacing: 100, Interpolation: Bilinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	length := GreatCircleDistance(path[0], path[1]) * 2
+	if math.Abs(profile.Length-length) > 1e-6 {
+		t.Error("profile length should be", length, "but was", profile.Length)
+	}
+	if n := int(math.Ceil(length/100)) + 2; len(profile.Points) != n {
+		t.Error("profile should have", n, "samples, but had", len(profile.Points))
+	}
+	for i := 1; i < len(profile.Points); i++ {
+		if step := profile.Points[i].Distance - profile.Points[i-1].Distance; step > 100+1e-9 || step <= 0 {
+			t.Fatal("samples should be at most 100 meters apart, but were", step)
+		}
+	}
+	if math.Abs(profile.Ascent-300) > 1 || math.Abs(profile.Descent-300) > 1 {
+		t.Error("profile should ascend and descend 300 meters, but were", profile.Ascent, profile.Descent)
+	}
+	if profile.MinElevation != 300 || profile.MaxElevation != 600 {
+		t.Error("profile should range from 300 to 600 meters, but was", profile.MinElevation, profile.MaxElevation)
+	}
+	// 1200 meters per degree of longitude
+	grade := 1200 / (length / 0.5) * 100
+	if math.Abs(profile.MaxGrade-grade) > 0.1 || math.Abs(profile.MinGrade+grade) > 0.1 {
+		t.Error("profile grades should be ±", grade, "but were", profile.MaxGrade, profile.MinGrade)
+	}
+
+	_, err = dataset.Profile(nil, ProfileOptions{})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Error("Profile without vertices should return an ErrInvalidPath error, but returned", err)
+	}
+	_, err = dataset.Profile([]LatLon{{48.5, 12.5}, {49.5, 12.5}}, ProfileOptions{})
+	if !errors.Is(err, ErrTileNotFound) {
+		t.Error("Profile across a missing tile should return an ErrTileNotFound error, but returned", err)
+	}
+	_, err = dataset.Profile([]LatLon{{48.5, 12.5}, {-48.5, -167.5}}, ProfileOptions{Spacing: 1000})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Error("Profile between antipodal vertices should return an ErrInvalidPath error, but returned", err)
+	}
+	_, err = dataset.Profile([]LatLon{{48.5, 12.5}, {-30, 100}}, ProfileOptions{Spacing: 1e-3})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Error("Profile with too many samples should return an ErrInvalidPath error, but returned", err)
+	}
+
+	// the samples stay on the multiples of the spacing along a long path
+	path = []LatLon{{48.01, 12.01}, {48.99, 12.99}, {48.01, 12.99}, {48.99, 12.01}}
+	profile, err = dataset.Profile(path, ProfileOptions{Spacing: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vertex := 0
+	for i, p := range profile.Points {
+		if vertex < len(path) && p.LatLon == path[vertex] {
+			vertex++
+			continue
+		}
+		if k := math.Round(p.Distance / 0.5); p.Distance != k*0.5 || k != float64(i-vertex+1) {
+			t.Fatal("sample", i, "at", p.Distance, "should be the multiple", i-vertex+1, "of the spacing")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package srtm
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxRasterSamples limits the size of rasters read from other file formats to the size of the
+// largest mosaic, so that malformed inputs cannot allocate unlimited memory,
+// while every mosaic written by this package can be read again.
+const maxRasterSamples = MaxMosaicSamples
+
+// checkRasterSize returns an error wrapping err if the raster exceeds maxRasterSamples.
+func checkRasterSize(width, height int, err error) error {
+	if samples := float64(width) * float64(height); samples > maxRasterSamples {
+		return fmt.Errorf("%w: %vx%v samples exceed the limit of %v", err, width, height, maxRasterSamples)
+	}
+	return nil
+}
+
+// rasterDecoders are the decoders of the file formats besides HGT read by OpenSRTMImage, by file extension.
+var rasterDecoders = map[string]func(io.Reader) (*SRTMImage, error){
+	".tif":  DecodeGeoTIFF,
+	".tiff": DecodeGeoTIFF,
+	".asc":  DecodeASCIIGrid,
+	".xyz":  DecodeXYZ,
+	".dt0":  DecodeDTED,
+	".dt1":  DecodeDTED,
+	".dt2":  DecodeDTED,
+}
+
+// rasterEncoders are the encoders of the file formats besides HGT written by WriteFile, by file extension.
+var rasterEncoders = map[string]func(*SRTMImage, io.Writer) error{
+	".tif": func(srtmImg *SRTMImage, w io.Writer) error {
+		return srtmImg.EncodeGeoTIFF(w, GeoTIFFOptions{Deflate: true})
+	},
+	".tiff": func(srtmImg *SRTMImage, w io.Writer) error {
+		return srtmImg.EncodeGeoTIFF(w, GeoTIFFOptions{Deflate: true})
+	},
+	".asc": (*SRTMImage).EncodeASCIIGrid,
+	".xyz": (*SRTMImage).EncodeXYZ,
+}
+
+// rasterExtension returns the lower case extension of the file name.
+func rasterExtension(name string) string {
+	return strings.ToLower(filepath.Ext(name))
+}
+
+// openRaster reads the file with the given name using decode.
+func openRaster(name string, decode func(io.Reader) (*SRTMImage, error)) (*SRTMImage, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return img, nil
+}
+
+// raster is a grid of elevations read from another file format, with its georeferencing.
+type raster struct {
+	data          []int16 // rounded elevations, VoidValue for no data
+	width, height int
+	// west and north are the longitude and latitude of the center of the first pixel,
+	// scaleX and scaleY the size of the pixels in degrees
+	west, north    float64
+	scaleX, scaleY float64
+}
+
+// rasterElevation rounds a sample read from another file format to an elevation.
+// The no data value, NaN and values out of the range of elevations are voids.
+func rasterElevation(v, noData float64) int16 {
+	if math.IsNaN(v) || v == noData || v < -32767 || v > 32767 {
+		return VoidValue
+	}
+	return roundElevation(v)
+}
+
+// resample converts the raster onto the SRTM grid of the tiles it covers.
+// Rasters whose SRTM grid would exceed maxRasterSamples return an error wrapping sentinel.
+func (g *raster) resample(sentinel error) (*SRTMImage, error) {
+	format := SRTM3Format
+	if g.scaleY*3600 <= 1.5 {
+		format = SRTM1Format
+	}
+	// the tiles covering the pixel centers, allowing for floating point noise on the whole degrees
+	const epsilon = 1e-6
+	south := g.north - float64(g.height-1)*g.scaleY
+	east := g.west + float64(g.width-1)*g.scaleX
+	southWest := Tile{int(math.Floor(south + epsilon)), int(math.Floor(g.west + epsilon))}
+	northEast := Tile{int(math.Ceil(g.north-epsilon)) - 1, int(math.Ceil(east-epsilon)) - 1}
+	if northEast.Lat < southWest.Lat {
+		northEast.Lat = southWest.Lat
+	}
+	if northEast.Lon < southWest.Lon {
+		northEast.Lon = southWest.Lon
+	}
+
+	columns, rows := northEast.Lon-southWest.Lon+1, northEast.Lat-southWest.Lat+1
+	if err := checkRasterSize(columns*(format.Size()-1)+1, rows*(format.Size()-1)+1, sentinel); err != nil {
+		return nil, fmt.Errorf("%w, covering %vx%v tiles", err, columns, rows)
+	}
+	img := newMosaic(format, southWest, columns, rows)
+	sample := func(x, y int) int16 {
+		return g.data[clamp(y, 0, g.height-1)*g.width+clamp(x, 0, g.width-1)]
+	}
+	width := img.Width()
+	for y := 0; y < img.Height(); y++ {
+		for x := 0; x < width; x++ {
+			lat, lon := img.FractionalPointToLatLon(float64(x), float64(y))
+			px, py := (lon-g.west)/g.scaleX, (g.north-lat)/g.scaleY
+			// the pixels extend half their size beyond their centers
+			if px < -0.5-epsilon || py < -0.5-epsilon || px > float64(g.width)-0.5+epsilon || py > float64(g.height)-0.5+epsilon {
+				continue
+			}
+			// snap to the pixel centers to read aligned grids exactly
+			if rx := math.Round(px); math.Abs(px-rx) < epsilon {
+				px = rx
+			}
+			if ry := math.Round(py); math.Abs(py-ry) < epsilon {
+				py = ry
+			}
+			if v, err := interpolate(sample, px, py, Bilinear); err == nil {
+				img.Data[y*width+x] = roundElevation(v)
+			}
+		}
+	}
+	return img, nil
+}
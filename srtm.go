@@ -2,11 +2,18 @@ package srtm
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 )
 
 var SRTMByteOrder = binary.BigEndian
 
+var ErrInvalidFormat = errors.New("invalid SRTM format")
+var ErrTruncated = errors.New("truncated SRTM data")
+var ErrTrailingData = errors.New("trailing data after SRTM data")
+var ErrUnknownTile = errors.New("SRTM tile of the image is unknown")
+
 type SRTMFormat int
 
 const (
@@ -42,10 +49,120 @@ func (f SRTMFormat) String() string {
 type SRTMImage struct {
 	Data   []int16
 	Format SRTMFormat
+	Tile   Tile // the cell the data belongs to, see ParseTile
+	// TileKnown is true if Tile was derived from a file name or georeferencing.
+	// The zero Tile is the valid tile N00E000, so data read without a name has an unknown tile.
+	TileKnown bool
+	// Columns and Rows are the number of tiles covered by a mosaic, see Mosaic,
+	// with Tile being its south-western tile. Zero values stand for a single tile.
+	Columns, Rows int
+}
+
+// Width returns the number of samples per row, Format.Size() for a single tile.
+func (srtmImg *SRTMImage) Width() int {
+	return atLeastOne(srtmImg.Columns)*(srtmImg.Format.Size()-1) + 1
+}
+
+// Height returns the number of rows, Format.Size() for a single tile.
+func (srtmImg *SRTMImage) Height() int {
+	return atLeastOne(srtmImg.Rows)*(srtmImg.Format.Size()-1) + 1
+}
+
+// IsMosaic returns true if the image covers more than a single tile.
+func (srtmImg *SRTMImage) IsMosaic() bool {
+	return srtmImg.Columns > 1 || srtmImg.Rows > 1
+}
+
+// setTile sets the tile of the image and marks it as known.
+func (srtmImg *SRTMImage) setTile(tile Tile) {
+	srtmImg.Tile = tile
+	srtmImg.TileKnown = true
+}
+
+// checkTileKnown returns ErrUnknownTile if the image is not georeferenced.
+func (srtmImg *SRTMImage) checkTileKnown() error {
+	if !srtmImg.TileKnown {
+		return fmt.Errorf("%w, name the file after its tile or set Tile and TileKnown", ErrUnknownTile)
+	}
+	return nil
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
 }
 
+// NewSRTMImage reads the elevation data of the given format from r.
+// It returns ErrTruncated if r ends before the data is complete.
+// Any data following the elevation data is not read, use Decoder to reject it.
 func NewSRTMImage(r io.Reader, format SRTMFormat) (*SRTMImage, error) {
-	data := make([]int16, format.Size()*format.Size())
-	err := binary.Read(r, SRTMByteOrder, data)
-	return &SRTMImage{data, format}, err
+	return Decoder{}.Decode(r, format)
+}
+
+// Decoder reads elevation data with additional checks and error context.
+type Decoder struct {
+	// Name is prepended to error messages, e.g. the name of the file being read.
+	// If it is a tile name as accepted by ParseTile, the tile of the image is set accordingly.
+	Name string
+	// Strict rejects inputs with data following the elevation data with ErrTrailingData.
+	Strict bool
+}
+
+// Decode reads the elevation data of the given format from r.
+// On failure no image is returned and the error wraps ErrInvalidFormat, ErrTruncated
+// or ErrTrailingData, stating the byte offset at which decoding stopped.
+func (d Decoder) Decode(r io.Reader, format SRTMFormat) (*SRTMImage, error) {
+	size := format.Size()
+	if size < 0 {
+		return nil, d.errorf("%w: %d", ErrInvalidFormat, format)
+	}
+
+	data := make([]int16, size*size)
+	buf := make([]byte, 64*1024)
+	offset := 0
+	for i := 0; i < len(data); {
+		chunk := buf
+		if remaining := (len(data) - i) * 2; remaining < len(chunk) {
+			chunk = chunk[:remaining]
+		}
+		n, err := io.ReadFull(r, chunk)
+		for j := 0; j+1 < n; j += 2 {
+			data[i] = int16(SRTMByteOrder.Uint16(chunk[j:]))
+			i++
+		}
+		offset += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, d.errorf("%w: %v, offset: %v of %v bytes", ErrTruncated, format, offset, len(data)*2)
+		}
+		if err != nil {
+			return nil, d.errorf("%v, offset: %v: %w", format, offset, err)
+		}
+	}
+
+	if d.Strict {
+		var b [1]byte
+		n, err := r.Read(b[:])
+		if n > 0 {
+			return nil, d.errorf("%w: %v, offset: %v", ErrTrailingData, format, offset)
+		}
+		if err != nil && err != io.EOF {
+			return nil, d.errorf("%v, offset: %v: %w", format, offset, err)
+		}
+	}
+	img := &SRTMImage{Data: data, Format: format}
+	if tile, err := ParseTile(d.Name); err == nil {
+		img.setTile(tile)
+	}
+	return img, nil
+}
+
+// errorf formats an error, prefixed with the name of the decoder if set.
+func (d Decoder) errorf(format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	if d.Name == "" {
+		return err
+	}
+	return fmt.Errorf("%v: %w", d.Name, err)
 }
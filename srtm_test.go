@@ -0,0 +1,67 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewSRTMImage(t *testing.T) {
+	data := make([]byte, 2884802)
+	data[0], data[1] = 0x80, 0x00
+	data[2], data[3] = 0x01, 0x02
+	img, err := NewSRTMImage(bytes.NewReader(data), SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Data[0] != -32768 || img.Data[1] != 258 {
+		t.Error("NewSRTMImage should decode big endian values -32768 and 258, but decoded", img.Data[:2])
+	}
+}
+
+func TestNewSRTMImageTruncated(t *testing.T) {
+	img, err := NewSRTMImage(bytes.NewReader(make([]byte, 1001)), SRTM3Format)
+	if img != nil {
+		t.Error("NewSRTMImage should not return an image on failure")
+	}
+	if errors.Is(err, ErrTruncated) == false {
+		t.Error("NewSRTMImage should return an ErrTruncated error, but returned", err)
+	}
+	if err.Error() != "truncated SRTM data: SRTM3, offset: 1001 of 2884802 bytes" {
+		t.Error("NewSRTMImage returned unexpected error message:", err)
+	}
+
+	_, err = Decoder{Name: "N48E012.hgt"}.Decode(bytes.NewReader(nil), SRTM1Format)
+	if errors.Is(err, ErrTruncated) == false || !strings.HasPrefix(err.Error(), "N48E012.hgt: ") {
+		t.Error("Decode should return an ErrTruncated error prefixed with the name, but returned", err)
+	}
+}
+
+func TestNewSRTMImageInvalidFormat(t *testing.T) {
+	img, err := NewSRTMImage(bytes.NewReader(nil), SRTMFormat(-1))
+	if img != nil || errors.Is(err, ErrInvalidFormat) == false {
+		t.Error("NewSRTMImage should return an ErrInvalidFormat error, but returned", err)
+	}
+}
+
+func TestDecoderStrict(t *testing.T) {
+	data := make([]byte, 2884802+2)
+	img, err := NewSRTMImage(bytes.NewReader(data), SRTM3Format)
+	if img == nil || err != nil {
+		t.Error("NewSRTMImage should ignore trailing data, but returned", err)
+	}
+
+	img, err = Decoder{Name: "N48E012.hgt", Strict: true}.Decode(bytes.NewReader(data), SRTM3Format)
+	if img != nil || errors.Is(err, ErrTrailingData) == false {
+		t.Error("strict Decode should return an ErrTrailingData error, but returned", err)
+	}
+	if err.Error() != "N48E012.hgt: trailing data after SRTM data: SRTM3, offset: 2884802" {
+		t.Error("strict Decode returned unexpected error message:", err)
+	}
+
+	img, err = Decoder{Strict: true}.Decode(bytes.NewReader(data[:2884802]), SRTM3Format)
+	if img == nil || err != nil {
+		t.Error("strict Decode should accept exactly sized data, but returned", err)
+	}
+}
@@ -0,0 +1,216 @@
+package srtm
+
+import (
+	"image"
+	"math"
+)
+
+// GradientAlgorithm selects the kernel used to estimate the surface gradient of the terrain.
+type GradientAlgorithm int
+
+const (
+	// Horn uses the weighted 3x3 neighbourhood, which is robust against noise.
+	Horn = GradientAlgorithm(iota)
+	// ZevenbergenThorne uses the four direct neighbours, which preserves more detail on smooth terrain.
+	ZevenbergenThorne
+)
+
+func (a GradientAlgorithm) String() string {
+	switch a {
+	case Horn:
+		return "Horn"
+	case ZevenbergenThorne:
+		return "Zevenbergen-Thorne"
+	}
+	return "invalid gradient algorithm"
+}
+
+// SlopeUnit selects the unit of slope values.
+type SlopeUnit int
+
+const (
+	SlopeDegrees = SlopeUnit(iota)
+	SlopePercent
+)
+
+// FlatAspect is the aspect of samples without any slope.
+const FlatAspect = -1
+
+// Float32Grid is a raster of derived values in the layout of the elevation data.
+// Samples without data, e.g. next to voids, are NaN.
+type Float32Grid struct {
+	Data   []float32
+	Width  int
+	Height int
+}
+
+// NewFloat32Grid returns a grid of the given dimensions with all samples set to 0.
+func NewFloat32Grid(width, height int) *Float32Grid {
+	return &Float32Grid{Data: make([]float32, width*height), Width: width, Height: height}
+}
+
+// At returns the value at the given coordinates.
+func (g *Float32Grid) At(point image.Point) float32 {
+	return g.Data[point.Y*g.Width+point.X]
+}
+
+// GrayImage scales the values linearly from min to max onto the brightness values 0 to 255.
+// Values outside of the range are clamped and NaN values are black.
+func (g *Float32Grid) GrayImage(min, max float32) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, g.Width, g.Height))
+	scale := 255 / (max - min)
+	for i, v := range g.Data {
+		if math.IsNaN(float64(v)) {
+			continue
+		}
+		scaled := (v - min) * scale
+		if scaled < 0 {
+			scaled = 0
+		} else if scaled > 255 {
+			scaled = 255
+		}
+		img.Pix[i] = uint8(scaled + 0.5)
+	}
+	return img
+}
+
+// Slope returns the steepness of the terrain for every sample.
+// The ground distance between samples is derived from the latitude of the tile,
+// as the east-west spacing of the arc-second grid shrinks towards the poles.
+// Samples next to voids are NaN. Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) Slope(algorithm GradientAlgorithm, unit SlopeUnit) (*Float32Grid, error) {
+	grid := NewFloat32Grid(srtmImg.Width(), srtmImg.Height())
+	err := srtmImg.gradients(algorithm, func(i int, dzdx, dzdn float64, ok bool) {
+		if !ok {
+			grid.Data[i] = float32(math.NaN())
+			return
+		}
+		rise := math.Hypot(dzdx, dzdn)
+		if unit == SlopePercent {
+			grid.Data[i] = float32(rise * 100)
+		} else {
+			grid.Data[i] = float32(math.Atan(rise) * 180 / math.Pi)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grid, nil
+}
+
+// Aspect returns the compass direction the terrain faces for every sample, in degrees
+// clockwise from north. Flat samples have the value FlatAspect, samples next to voids are NaN.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) Aspect(algorithm GradientAlgorithm) (*Float32Grid, error) {
+	grid := NewFloat32Grid(srtmImg.Width(), srtmImg.Height())
+	err := srtmImg.gradients(algorithm, func(i int, dzdx, dzdn float64, ok bool) {
+		if !ok {
+			grid.Data[i] = float32(math.NaN())
+			return
+		}
+		if dzdx == 0 && dzdn == 0 {
+			grid.Data[i] = FlatAspect
+			return
+		}
+		// the terrain faces downhill, opposite to the gradient
+		aspect := math.Atan2(-dzdx, -dzdn) * 180 / math.Pi
+		if aspect < 0 {
+			aspect += 360
+		}
+		grid.Data[i] = float32(aspect)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grid, nil
+}
+
+// SlopeImage returns the slope in degrees as a grayscale image,
+// where flat terrain is black and vertical terrain is white.
+func (srtmImg *SRTMImage) SlopeImage(algorithm GradientAlgorithm) (*image.Gray, error) {
+	slope, err := srtmImg.Slope(algorithm, SlopeDegrees)
+	if err != nil {
+		return nil, err
+	}
+	return slope.GrayImage(0, 90), nil
+}
+
+// AspectImage returns the aspect as a grayscale image, where north is black
+// and the brightness increases clockwise. Flat terrain and voids are black as well.
+func (srtmImg *SRTMImage) AspectImage(algorithm GradientAlgorithm) (*image.Gray, error) {
+	aspect, err := srtmImg.Aspect(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return aspect.GrayImage(0, 360), nil
+}
+
+// metersPerDegree returns the length of one degree of latitude and longitude
+// on the WGS84 ellipsoid at the given latitude.
+func metersPerDegree(lat float64) (latMeters, lonMeters float64) {
+	phi := lat * math.Pi / 180
+	latMeters = 111132.92 - 559.82*math.Cos(2*phi) + 1.175*math.Cos(4*phi) - 0.0023*math.Cos(6*phi)
+	lonMeters = 111412.84*math.Cos(phi) - 93.5*math.Cos(3*phi) + 0.118*math.Cos(5*phi)
+	return
+}
+
+// gradients calls fn with the elevation gradient of every sample, in meters per meter
+// towards the east and the north. Edge samples replicate their neighbours.
+// ok is false if the gradient cannot be computed because of voids.
+// The spacing of the samples depends on the latitude, so the tile must be known.
+func (srtmImg *SRTMImage) gradients(algorithm GradientAlgorithm, fn func(i int, dzdx, dzdn float64, ok bool)) error {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+	width, height := srtmImg.Width(), srtmImg.Height()
+	spacing := 1 / float64(srtmImg.Format.Size()-1)
+
+	// samples of the neighbourhood used by the algorithm, including the center
+	kernel := [9]bool{true, true, true, true, true, true, true, true, true}
+	if algorithm == ZevenbergenThorne {
+		kernel = [9]bool{false, true, false, true, true, true, false, true, false}
+	}
+
+	for y := 0; y < height; y++ {
+		lat, _ := srtmImg.PointToLatLon(image.Point{0, y})
+		latMeters, lonMeters := metersPerDegree(lat)
+		dx, dy := spacing*lonMeters, spacing*latMeters
+
+		for x := 0; x < width; x++ {
+			// 3x3 neighbourhood, n[0] is the north-west and n[8] the south-east sample
+			var n [9]float64
+			ok := true
+			for k := range n {
+				v := srtmImg.clampedAt(x+k%3-1, y+k/3-1)
+				if v == VoidValue && kernel[k] {
+					ok = false
+					break
+				}
+				n[k] = float64(v)
+			}
+
+			// the differences span two samples, except for the replicated edges
+			spanX, spanY := 2.0, 2.0
+			if x == 0 || x == width-1 {
+				spanX = 1
+			}
+			if y == 0 || y == height-1 {
+				spanY = 1
+			}
+
+			var dzdx, dzdn float64
+			if ok {
+				switch algorithm {
+				case ZevenbergenThorne:
+					dzdx = (n[5] - n[3]) / (spanX * dx)
+					dzdn = (n[1] - n[7]) / (spanY * dy)
+				default:
+					dzdx = ((n[2] + 2*n[5] + n[8]) - (n[0] + 2*n[3] + n[6])) / (4 * spanX * dx)
+					dzdn = ((n[0] + 2*n[1] + n[2]) - (n[6] + 2*n[7] + n[8])) / (4 * spanY * dy)
+				}
+			}
+			fn(y*width+x, dzdx, dzdn, ok)
+		}
+	}
+	return nil
+}
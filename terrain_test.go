@@ -0,0 +1,103 @@
+package srtm
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSlopeAspect(t *testing.T) {
+	// rising by one meter per sample towards the east on the equator
+	img := newTestImage(Tile{0, 0}, func(x, y int) int16 { return int16(x) })
+	_, lonMeters := metersPerDegree(0.5)
+	expected := 1 / (lonMeters / 1200)
+
+	for _, algorithm := range []GradientAlgorithm{Horn, ZevenbergenThorne} {
+		percent, err := img.Slope(algorithm, SlopePercent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, point := range []image.Point{{600, 600}, {0, 600}, {1200, 0}} {
+			if v := float64(percent.At(point)); math.Abs(v-expected*100) > 0.01 {
+				t.Errorf("%v: slope at %v should be %v%%, but was %v", algorithm, point, expected*100, v)
+			}
+		}
+		degrees, err := img.Slope(algorithm, SlopeDegrees)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := float64(degrees.At(image.Point{600, 600})); math.Abs(v-math.Atan(expected)*180/math.Pi) > 0.001 {
+			t.Errorf("%v: slope in degrees was %v", algorithm, v)
+		}
+		// rising towards the east means facing west
+		aspect, err := img.Aspect(algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := aspect.At(image.Point{600, 600}); v != 270 {
+			t.Errorf("%v: aspect should be 270, but was %v", algorithm, v)
+		}
+	}
+}
+
+func TestSlopeLatitude(t *testing.T) {
+	// rising towards the east at high latitude is steeper, as samples are closer together
+	equator := newTestImage(Tile{0, 0}, func(x, y int) int16 { return int16(x) })
+	north := newTestImage(Tile{60, 0}, func(x, y int) int16 { return int16(x) })
+	equatorSlope, err := equator.Slope(Horn, SlopePercent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	northSlope, err := north.Slope(Horn, SlopePercent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := equatorSlope.At(image.Point{600, 600}), northSlope.At(image.Point{600, 600})
+	if ratio := float64(b / a); math.Abs(ratio-1/math.Cos(60.5*math.Pi/180)) > 0.01 {
+		t.Error("slope at 60 degrees north should be steeper by 1/cos(lat), but ratio was", ratio)
+	}
+
+	// rising towards the north means facing south, independent of the latitude
+	img := newTestImage(Tile{60, 0}, func(x, y int) int16 { return int16(1200 - y) })
+	aspect, err := img.Aspect(ZevenbergenThorne)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := aspect.At(image.Point{600, 600}); v != 180 {
+		t.Error("aspect should be 180, but was", v)
+	}
+}
+
+func TestSlopeVoidsAndFlat(t *testing.T) {
+	img := newTestImage(Tile{0, 0}, func(x, y int) int16 { return 100 })
+	img.Data[10*1201+10] = VoidValue
+
+	horn, err := img.Slope(Horn, SlopeDegrees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(float64(horn.At(image.Point{11, 11}))) {
+		t.Error("Horn slope next to a void should be NaN, but was", horn.At(image.Point{11, 11}))
+	}
+	zt, err := img.Slope(ZevenbergenThorne, SlopeDegrees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := zt.At(image.Point{11, 11}); v != 0 {
+		t.Error("Zevenbergen-Thorne slope diagonal to a void should be 0, but was", v)
+	}
+	aspect, err := img.Aspect(Horn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := aspect.At(image.Point{600, 600}); v != FlatAspect {
+		t.Error("aspect of flat terrain should be FlatAspect, but was", v)
+	}
+	gray, err := img.SlopeImage(Horn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gray.GrayAt(600, 600).Y != 0 || gray.GrayAt(11, 11).Y != 0 {
+		t.Error("SlopeImage of flat terrain and voids should be black")
+	}
+}
@@ -0,0 +1,158 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+var ErrInvalidWebTile = errors.New("invalid web mercator tile")
+var ErrInvalidRGBEncoding = errors.New("invalid RGB elevation encoding")
+
+// RGBEncoding packs elevations into the red, green and blue channels of an image,
+// as read by the terrain layers of web maps like Mapbox GL and MapLibre:
+//
+//	elevation = Base + (R*65536 + G*256 + B) * Interval
+type RGBEncoding struct {
+	// Base is the elevation of black in meters.
+	Base float64
+	// Interval is the elevation step between consecutive values in meters, it must be positive.
+	Interval float64
+}
+
+var (
+	// TerrainRGB is the Mapbox Terrain-RGB encoding with a precision of 0.1 m.
+	TerrainRGB = RGBEncoding{Base: -10000, Interval: 0.1}
+	// Terrarium is the Mapzen Terrarium encoding, elevation = R*256 + G + B/256 - 32768.
+	Terrarium = RGBEncoding{Base: -32768, Interval: 1.0 / 256}
+)
+
+// check returns ErrInvalidRGBEncoding unless the base is finite and the interval is positive and finite.
+func (e RGBEncoding) check() error {
+	if math.IsNaN(e.Base) || math.IsInf(e.Base, 0) || !(e.Interval > 0) || math.IsInf(e.Interval, 0) {
+		return fmt.Errorf("%w: base: %v, interval: %v", ErrInvalidRGBEncoding, e.Base, e.Interval)
+	}
+	return nil
+}
+
+// Color returns the opaque color encoding the elevation, which is rounded to the nearest interval
+// and clamped to the encodable range. Web maps have no notion of voids, so NaN is encoded as sea level.
+// An encoding with an interval which is not positive has no valid colors and returns black.
+func (e RGBEncoding) Color(elevation float64) color.NRGBA {
+	if math.IsNaN(elevation) {
+		elevation = 0
+	}
+	v := math.Round((elevation - e.Base) / e.Interval)
+	if !(e.Interval > 0) || math.IsNaN(v) || v < 0 {
+		v = 0
+	} else if v > 0xffffff {
+		v = 0xffffff
+	}
+	n := uint32(v)
+	return color.NRGBA{uint8(n >> 16), uint8(n >> 8), uint8(n), 255}
+}
+
+// Elevation returns the elevation encoded by the color. Transparent colors have no elevation and return NaN.
+func (e RGBEncoding) Elevation(c color.NRGBA) float64 {
+	if c.A == 0 {
+		return math.NaN()
+	}
+	return e.Base + float64(uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))*e.Interval
+}
+
+// RGBElevationImage encodes the elevation data into an image with the given encoding, e.g. TerrainRGB or Terrarium.
+// Voids are encoded as sea level, use FillVoids beforehand to interpolate them.
+// Encodings without a positive interval return ErrInvalidRGBEncoding.
+func (srtmImg *SRTMImage) RGBElevationImage(encoding RGBEncoding) (*image.NRGBA, error) {
+	if err := encoding.check(); err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, srtmImg.Width(), srtmImg.Height()))
+	for i, v := range srtmImg.Data {
+		elevation := float64(v)
+		if v == VoidValue {
+			elevation = math.NaN()
+		}
+		c := encoding.Color(elevation)
+		copy(img.Pix[i*4:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	return img, nil
+}
+
+// RGBElevationImage encodes the grid of elevations, e.g. returned by WebMercatorTile, into an image
+// with the given encoding. NaN values are encoded as sea level.
+// Encodings without a positive interval return ErrInvalidRGBEncoding.
+func (g *Float32Grid) RGBElevationImage(encoding RGBEncoding) (*image.NRGBA, error) {
+	if err := encoding.check(); err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, g.Width, g.Height))
+	for i, v := range g.Data {
+		c := encoding.Color(float64(v))
+		copy(img.Pix[i*4:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	return img, nil
+}
+
+// DecodeRGBElevationImage decodes the elevations of an image with the given encoding.
+// Transparent pixels are NaN.
+func DecodeRGBElevationImage(img image.Image, encoding RGBEncoding) *Float32Grid {
+	bounds := img.Bounds()
+	grid := NewFloat32Grid(bounds.Dx(), bounds.Dy())
+	for y := 0; y < grid.Height; y++ {
+		for x := 0; x < grid.Width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			grid.Data[y*grid.Width+x] = float32(encoding.Elevation(c))
+		}
+	}
+	return grid
+}
+
+// maxMercatorLatitude is the latitude of the north edge of the web mercator tile 0/0/0.
+var maxMercatorLatitude = math.Atan(math.Sinh(math.Pi)) * 180 / math.Pi
+
+// WebMercatorTileAt returns the x and y index of the web mercator tile at the given zoom level containing lat/lon,
+// as used by the z/x/y tile URLs of web maps. Latitudes beyond ±85.0511° are clamped to the first and last row.
+func WebMercatorTileAt(lat, lon float64, zoom int) (x, y int) {
+	n := 1 << zoom
+	lat = math.Max(-maxMercatorLatitude, math.Min(maxMercatorLatitude, lat))
+	fx := (lon + 180) / 360 * float64(n)
+	sinLat := math.Sin(lat * math.Pi / 180)
+	fy := (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * float64(n)
+	return clamp(int(math.Floor(fx)), 0, n-1), clamp(int(math.Floor(fy)), 0, n-1)
+}
+
+// webMercatorToLatLon converts the fractional tile coordinates at the given zoom level to latitude/longitude.
+func webMercatorToLatLon(fx, fy float64, zoom int) (lat, lon float64) {
+	n := float64(int(1) << zoom)
+	lon = fx/n*360 - 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*fy/n))) * 180 / math.Pi
+	return lat, lon
+}
+
+// WebMercatorTile samples the elevations at the pixel centers of the web mercator tile zoom/x/y
+// with size x size pixels, ready to be encoded with RGBElevationImage for a terrain layer.
+// Ocean tiles are at sea level, samples on voids or on tiles missing from the dataset are NaN.
+func (d *Dataset) WebMercatorTile(zoom, x, y, size int, interpolation Interpolation) (*Float32Grid, error) {
+	if zoom < 0 || zoom > 30 || x < 0 || y < 0 || x >= 1<<zoom || y >= 1<<zoom || size <= 0 {
+		return nil, fmt.Errorf("%w: %v/%v/%v, size: %v", ErrInvalidWebTile, zoom, x, y, size)
+	}
+	grid := NewFloat32Grid(size, size)
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			lat, lon := webMercatorToLatLon(float64(x)+(float64(px)+0.5)/float64(size), float64(y)+(float64(py)+0.5)/float64(size), zoom)
+			elevation, err := d.ElevationAt(lat, lon, interpolation)
+			switch {
+			case err == nil, errors.Is(err, ErrOcean):
+			case errors.Is(err, ErrVoid), errors.Is(err, ErrTileNotFound):
+				elevation = math.NaN()
+			default:
+				return nil, err
+			}
+			grid.Data[py*size+px] = float32(elevation)
+		}
+	}
+	return grid, nil
+}
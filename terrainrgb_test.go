@@ -0,0 +1,138 @@
+package srtm
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestRGBEncodingColor(t *testing.T) {
+	for _, c := range []struct {
+		encoding  RGBEncoding
+		elevation float64
+		color     color.NRGBA
+	}{
+		{TerrainRGB, 0, color.NRGBA{1, 134, 160, 255}},
+		{TerrainRGB, -10000, color.NRGBA{0, 0, 0, 255}},
+		{TerrainRGB, -20000, color.NRGBA{0, 0, 0, 255}},
+		{TerrainRGB, 8848.3, color.NRGBA{2, 224, 67, 255}},
+		{Terrarium, 0, color.NRGBA{128, 0, 0, 255}},
+		{Terrarium, 100.5, color.NRGBA{128, 100, 128, 255}},
+		{Terrarium, -100, color.NRGBA{127, 156, 0, 255}},
+		{RGBEncoding{Base: 0, Interval: 1}, 65537, color.NRGBA{1, 0, 1, 255}},
+		{TerrainRGB, math.NaN(), color.NRGBA{1, 134, 160, 255}},
+	} {
+		if got := c.encoding.Color(c.elevation); got != c.color {
+			t.Error(c.encoding, "should encode", c.elevation, "as", c.color, "but returned", got)
+		}
+		if math.IsNaN(c.elevation) || c.elevation < c.encoding.Base {
+			continue
+		}
+		if got := c.encoding.Elevation(c.color); math.Abs(got-c.elevation) > c.encoding.Interval/2+1e-9 {
+			t.Error(c.encoding, "should decode", c.color, "as", c.elevation, "but returned", got)
+		}
+	}
+	if e := TerrainRGB.Elevation(color.NRGBA{1, 134, 160, 0}); !math.IsNaN(e) {
+		t.Error("transparent colors should decode as NaN, but returned", e)
+	}
+}
+
+func TestRGBElevationImage(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x - y) })
+	img.Data[1201*5+7] = VoidValue
+
+	for _, encoding := range []RGBEncoding{TerrainRGB, Terrarium} {
+		encoded, err := img.RGBElevationImage(encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded.Bounds() != image.Rect(0, 0, 1201, 1201) {
+			t.Fatal("image should be 1201x1201, but was", encoded.Bounds())
+		}
+		grid := DecodeRGBElevationImage(encoded, encoding)
+		for i, v := range img.Data {
+			expected := float32(v)
+			if v == VoidValue {
+				expected = 0
+			}
+			if math.Abs(float64(grid.Data[i]-expected)) > 1e-3 {
+				t.Fatal(encoding, "should decode sample", i, "as", expected, "but returned", grid.Data[i])
+			}
+		}
+	}
+
+	for _, encoding := range []RGBEncoding{{Base: 0, Interval: 0}, {Base: 0, Interval: -1}, {Base: math.NaN(), Interval: 1}} {
+		if _, err := img.RGBElevationImage(encoding); !errors.Is(err, ErrInvalidRGBEncoding) {
+			t.Error(encoding, "should return an ErrInvalidRGBEncoding error, but returned", err)
+		}
+		if _, err := NewFloat32Grid(1, 1).RGBElevationImage(encoding); !errors.Is(err, ErrInvalidRGBEncoding) {
+			t.Error(encoding, "should return an ErrInvalidRGBEncoding error for grids, but returned", err)
+		}
+		if c := encoding.Color(100); c != (color.NRGBA{0, 0, 0, 255}) {
+			t.Error(encoding, "should encode black, but returned", c)
+		}
+	}
+}
+
+func TestWebMercatorTileAt(t *testing.T) {
+	for _, c := range []struct {
+		lat, lon float64
+		zoom     int
+		x, y     int
+	}{
+		{0, 0, 0, 0, 0},
+		{10, -10, 1, 0, 0},
+		{-10, 10, 1, 1, 1},
+		{89, 179.9, 2, 3, 0},
+		{-89, -180, 2, 0, 3},
+		{48.1372, 11.5756, 10, 544, 355},
+	} {
+		if x, y := WebMercatorTileAt(c.lat, c.lon, c.zoom); x != c.x || y != c.y {
+			t.Error("tile at", c.lat, c.lon, "zoom", c.zoom, "should be", c.x, c.y, "but was", x, y)
+		}
+	}
+}
+
+func TestWebMercatorTile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 { return 500 }))
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, y := WebMercatorTileAt(48.5, 12.5, 10)
+	grid, err := dataset.WebMercatorTile(10, x, y, 64, Bilinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range grid.Data {
+		if v != 500 {
+			t.Fatal("sample", i, "inside of N48E012 should be 500, but was", v)
+		}
+	}
+
+	// the tile at zoom 7 extends beyond N48E012
+	x, y = WebMercatorTileAt(48.5, 12.5, 7)
+	grid, err = dataset.WebMercatorTile(7, x, y, 256, Bilinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := 0
+	for _, v := range grid.Data {
+		if v == 500 {
+			valid++
+		} else if !math.IsNaN(float64(v)) {
+			t.Fatal("samples should be 500 or NaN, but were", v)
+		}
+	}
+	if valid == 0 || valid == len(grid.Data) {
+		t.Error("the tile should partly cover N48E012, but covered", valid, "samples")
+	}
+
+	if _, err := dataset.WebMercatorTile(2, 4, 0, 256, Bilinear); !errors.Is(err, ErrInvalidWebTile) {
+		t.Error("tile 2/4/0 should return an ErrInvalidWebTile error, but returned", err)
+	}
+}
@@ -0,0 +1,104 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidTileName = errors.New("invalid SRTM tile name")
+var ErrInvalidTile = errors.New("invalid SRTM tile coordinates")
+
+// tileNamePattern matches the leading cell identifier of HGT file names,
+// e.g. N48E012.hgt, s33w071.HGT or N48E012.SRTMGL1.hgt.zip.
+var tileNamePattern = regexp.MustCompile(`^([NSns])(\d{1,2})([EWew])(\d{1,3})(?:$|\.)`)
+
+// Tile identifies a one by one degree SRTM cell.
+// As per the SRTM documentation the tile is named after its southwest corner,
+// which refers to the geometric center of the lower left sample.
+type Tile struct {
+	Lat int // latitude of the southwest corner in degrees, -90 to 89
+	Lon int // longitude of the southwest corner in degrees, -180 to 179
+}
+
+// NewTile returns the tile with the given southwest corner.
+func NewTile(lat, lon int) (Tile, error) {
+	tile := Tile{lat, lon}
+	if !tile.IsValid() {
+		return Tile{}, fmt.Errorf("%w: lat: %v, lon: %v", ErrInvalidTile, lat, lon)
+	}
+	return tile, nil
+}
+
+// ParseTile parses the tile identity from a HGT file name.
+// Directories and any extensions following the cell identifier are ignored,
+// so that N48E012.hgt, s33w071.HGT and N48E012.SRTMGL1.hgt.zip are all accepted.
+func ParseTile(name string) (Tile, error) {
+	base := filepath.Base(name)
+	match := tileNamePattern.FindStringSubmatch(base)
+	if match == nil {
+		return Tile{}, fmt.Errorf("%w: %v", ErrInvalidTileName, base)
+	}
+	// the pattern guarantees the digits, so conversion cannot fail
+	lat, _ := strconv.Atoi(match[2])
+	lon, _ := strconv.Atoi(match[4])
+	if strings.EqualFold(match[1], "S") {
+		lat = -lat
+	}
+	if strings.EqualFold(match[3], "W") {
+		lon = -lon
+	}
+
+	tile := Tile{lat, lon}
+	if !tile.IsValid() {
+		return Tile{}, fmt.Errorf("%w: %v", ErrInvalidTileName, base)
+	}
+	return tile, nil
+}
+
+// IsValid returns true if the southwest corner of the tile lies on the globe.
+func (t Tile) IsValid() bool {
+	return t.Lat >= -90 && t.Lat < 90 && t.Lon >= -180 && t.Lon < 180
+}
+
+// String returns the canonical cell identifier, e.g. N48E012 or S33W071.
+func (t Tile) String() string {
+	ns, ew := 'N', 'E'
+	lat, lon := t.Lat, t.Lon
+	if lat < 0 {
+		ns, lat = 'S', -lat
+	}
+	if lon < 0 {
+		ew, lon = 'W', -lon
+	}
+	return fmt.Sprintf("%c%02d%c%03d", ns, lat, ew, lon)
+}
+
+// Filename returns the canonical HGT file name of the tile, e.g. N48E012.hgt.
+func (t Tile) Filename() string {
+	return t.String() + ".hgt"
+}
+
+// TileAt returns the tile containing the given WGS84 latitude/longitude.
+// Points on the shared edge of two tiles belong to the northern and eastern tile respectively,
+// except for the north pole and the antimeridian, which wrap to the last tile row and -180 degrees.
+func TileAt(lat, lon float64) Tile {
+	tileLat := int(math.Floor(lat))
+	if tileLat >= 90 {
+		tileLat = 89
+	}
+	return Tile{tileLat, wrapLon(int(math.Floor(lon)))}
+}
+
+// wrapLon wraps a longitude in degrees into the range -180 to 179.
+func wrapLon(lon int) int {
+	lon = (lon + 180) % 360
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
@@ -0,0 +1,67 @@
+package srtm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTile(t *testing.T) {
+	tile, err := ParseTile("N48E012.hgt")
+	if err != nil || tile.Lat != 48 || tile.Lon != 12 {
+		t.Error("ParseTile(N48E012.hgt) should return (48,12), but returned", tile, err)
+	}
+	tile, err = ParseTile("s33w071.HGT")
+	if err != nil || tile.Lat != -33 || tile.Lon != -71 {
+		t.Error("ParseTile(s33w071.HGT) should return (-33,-71), but returned", tile, err)
+	}
+	tile, err = ParseTile("/data/srtm/N48E012.SRTMGL1.hgt.zip")
+	if err != nil || tile.Lat != 48 || tile.Lon != 12 {
+		t.Error("ParseTile(N48E012.SRTMGL1.hgt.zip) should return (48,12), but returned", tile, err)
+	}
+	tile, err = ParseTile("N48E12")
+	if err != nil || tile.Lat != 48 || tile.Lon != 12 {
+		t.Error("ParseTile(N48E12) should return (48,12), but returned", tile, err)
+	}
+}
+
+func TestParseTileError(t *testing.T) {
+	names := []string{"", "foo.hgt", "N48E012x.hgt", "N90E000.hgt", "N00E180.hgt", "X48E012.hgt"}
+	for _, name := range names {
+		_, err := ParseTile(name)
+		if err == nil {
+			t.Errorf("ParseTile(%q) should return an error, but returned nil", name)
+		}
+		if errors.Is(err, ErrInvalidTileName) == false {
+			t.Errorf("ParseTile(%q) should return an ErrInvalidTileName error, but returned %v", name, err)
+		}
+	}
+}
+
+func TestTileString(t *testing.T) {
+	if s := (Tile{48, 12}).String(); s != "N48E012" {
+		t.Error("Tile{48,12}.String() should return N48E012, but returned", s)
+	}
+	if s := (Tile{-33, -71}).String(); s != "S33W071" {
+		t.Error("Tile{-33,-71}.String() should return S33W071, but returned", s)
+	}
+	if s := (Tile{0, 0}).Filename(); s != "N00E000.hgt" {
+		t.Error("Tile{0,0}.Filename() should return N00E000.hgt, but returned", s)
+	}
+	for _, name := range []string{"N48E012", "S33W071", "S01W001", "N89W180"} {
+		tile, _ := ParseTile(name)
+		if tile.String() != name {
+			t.Errorf("ParseTile(%q).String() should round trip, but returned %v", name, tile)
+		}
+	}
+}
+
+func TestNewTileError(t *testing.T) {
+	_, err := NewTile(90, 0)
+	if errors.Is(err, ErrInvalidTile) == false {
+		t.Error("NewTile(90,0) should return an ErrInvalidTile error, but returned", err)
+	}
+	_, err = NewTile(0, -181)
+	if errors.Is(err, ErrInvalidTile) == false {
+		t.Error("NewTile(0,-181) should return an ErrInvalidTile error, but returned", err)
+	}
+}
@@ -0,0 +1,148 @@
+package srtm
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+)
+
+// TileReader provides access to HGT data without reading the complete grid into memory.
+// Every request reads exactly the big-endian samples it needs from the underlying io.ReaderAt,
+// which makes it suitable for point lookups on many tiles.
+// A TileReader is safe for concurrent use, if the underlying io.ReaderAt is.
+type TileReader struct {
+	Format SRTMFormat
+	Tile   Tile
+	// TileKnown is true if Tile was derived from the file name, see SRTMImage.TileKnown.
+	// Lookups by latitude/longitude require a known tile.
+	TileKnown bool
+
+	r      io.ReaderAt
+	closer io.Closer
+}
+
+// NewTileReader returns a TileReader reading HGT data of the given format from r.
+// It returns an ErrInvalidFormat error, if the format is unknown.
+// The tile is unknown, set Tile and TileKnown for lookups by latitude/longitude.
+func NewTileReader(r io.ReaderAt, format SRTMFormat) (*TileReader, error) {
+	if format.Size() <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidFormat, format)
+	}
+	return &TileReader{Format: format, r: r}, nil
+}
+
+// OpenTileReader opens the uncompressed HGT file with the given name,
+// detecting its format from the file size and its tile from the file name.
+// On Linux the file is memory-mapped, elsewhere it is read with ReadAt.
+// The TileReader must be closed after use.
+func OpenTileReader(name string) (*TileReader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	format, err := FormatFromSize(info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+
+	// mapFile takes ownership of f and closes it on failure.
+	r, err := mapFile(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	tileReader := &TileReader{Format: format, r: r, closer: r}
+	if tile, err := ParseTile(name); err == nil {
+		tileReader.Tile = tile
+		tileReader.TileKnown = true
+	}
+	return tileReader, nil
+}
+
+// Close releases the file opened by OpenTileReader.
+// It does nothing for TileReaders created by NewTileReader.
+func (t *TileReader) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// ElevationAt returns the elevation value at the given coordinates.
+func (t *TileReader) ElevationAt(point image.Point) (int16, error) {
+	index, err := CoordinatesToIndex(point, t.Format)
+	if err != nil {
+		return -1, err
+	}
+	var buf [2]byte
+	if _, err := t.r.ReadAt(buf[:], int64(index)*2); err != nil {
+		return -1, err
+	}
+	return int16(SRTMByteOrder.Uint16(buf[:])), nil
+}
+
+// Window returns the elevation values inside the rectangle in row major order.
+// The rectangle must lie completely inside the tile.
+func (t *TileReader) Window(rect image.Rectangle) ([]int16, error) {
+	if rect.Empty() {
+		return nil, nil
+	}
+	if !IsPointInBounds(rect.Min, t.Format) || !IsPointInBounds(rect.Max.Sub(image.Point{1, 1}), t.Format) {
+		return nil, fmt.Errorf("%w: %v, rectangle: %v", ErrPointOutOfBounds, t.Format, rect)
+	}
+
+	size := t.Format.Size()
+	width := rect.Dx()
+	data := make([]int16, width*rect.Dy())
+	buf := make([]byte, width*2)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		offset := int64(y*size+rect.Min.X) * 2
+		if _, err := t.r.ReadAt(buf, offset); err != nil {
+			return nil, err
+		}
+		row := data[(y-rect.Min.Y)*width:]
+		for x := 0; x < width; x++ {
+			row[x] = int16(SRTMByteOrder.Uint16(buf[x*2:]))
+		}
+	}
+	return data, nil
+}
+
+// ElevationAtLatLon returns the elevation at the given WGS84 latitude/longitude
+// like SRTMImage.ElevationAtLatLon, reading only the neighbouring samples required by the interpolation.
+// It returns ErrUnknownTile if the tile of the reader is not known.
+func (t *TileReader) ElevationAtLatLon(lat, lon float64, interpolation Interpolation) (float64, error) {
+	// an image without data is sufficient for the coordinate conversion
+	geo := &SRTMImage{Format: t.Format, Tile: t.Tile, TileKnown: t.TileKnown}
+	x, y, err := geo.latLonToPointInBounds(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+
+	// bicubic interpolation needs one sample before and two samples after the point
+	ix, iy := int(math.Floor(x)), int(math.Floor(y))
+	last := t.Format.Size() - 1
+	rect := image.Rect(clamp(ix-1, 0, last), clamp(iy-1, 0, last), clamp(ix+2, 0, last)+1, clamp(iy+2, 0, last)+1)
+	window, err := t.Window(rect)
+	if err != nil {
+		return 0, err
+	}
+	sample := func(x, y int) int16 {
+		x = clamp(x, rect.Min.X, rect.Max.X-1)
+		y = clamp(y, rect.Min.Y, rect.Max.Y-1)
+		return window[(y-rect.Min.Y)*rect.Dx()+x-rect.Min.X]
+	}
+	return interpolate(sample, x, y, interpolation)
+}
+
+type readerAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
@@ -0,0 +1,116 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTileReader(t *testing.T) {
+	dir := t.TempDir()
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x - y) })
+	writeTestTile(t, dir, img)
+
+	reader, err := OpenTileReader(filepath.Join(dir, "N48E012.hgt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if reader.Format != SRTM3Format || reader.Tile != (Tile{48, 12}) || !reader.TileKnown {
+		t.Error("OpenTileReader detected wrong format or tile", reader.Format, reader.Tile)
+	}
+
+	for _, point := range []image.Point{{0, 0}, {1200, 0}, {17, 42}, {1200, 1200}} {
+		v, err := reader.ElevationAt(point)
+		expected, _ := img.ElevationAt(point)
+		if err != nil || v != expected {
+			t.Errorf("ElevationAt(%v) should return %v, but returned %v %v", point, expected, v, err)
+		}
+	}
+	_, err = reader.ElevationAt(image.Point{1201, 0})
+	if errors.Is(err, ErrPointOutOfBounds) == false {
+		t.Error("ElevationAt(1201,0) should return an ErrPointOutOfBounds error, but returned", err)
+	}
+
+	window, err := reader.Window(image.Rect(1198, 5, 1201, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int16{1193, 1194, 1195, 1192, 1193, 1194}
+	for i := range expected {
+		if window[i] != expected[i] {
+			t.Fatal("Window should return", expected, "but returned", window)
+		}
+	}
+	_, err = reader.Window(image.Rect(1198, 5, 1202, 7))
+	if errors.Is(err, ErrPointOutOfBounds) == false {
+		t.Error("Window exceeding the tile should return an ErrPointOutOfBounds error, but returned", err)
+	}
+
+	for _, interpolation := range []Interpolation{NearestNeighbor, Bilinear, Bicubic} {
+		for _, latLon := range [][2]float64{{48.5, 12.5}, {48.0001, 12.9999}, {49, 12}, {48, 13}} {
+			v, err := reader.ElevationAtLatLon(latLon[0], latLon[1], interpolation)
+			expected, _ := img.ElevationAtLatLon(latLon[0], latLon[1], interpolation)
+			if err != nil || v != expected {
+				t.Errorf("%v: ElevationAtLatLon(%v) should return %v, but returned %v %v", interpolation, latLon, expected, v, err)
+			}
+		}
+	}
+}
+
+func TestNewTileReader(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x + y) })
+	var buf bytes.Buffer
+	if err := img.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewTileReader(bytes.NewReader(buf.Bytes()), SRTM3Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := reader.ElevationAt(image.Point{17, 42})
+	if err != nil || v != 59 {
+		t.Error("ElevationAt(17,42) should return 59, but returned", v, err)
+	}
+	if _, err := reader.ElevationAtLatLon(0.5, 0.5, Bilinear); !errors.Is(err, ErrUnknownTile) {
+		t.Error("ElevationAtLatLon without tile should return an ErrUnknownTile error, but returned", err)
+	}
+	reader.Tile, reader.TileKnown = Tile{48, 12}, true
+	expected, _ := img.ElevationAtLatLon(48.5, 12.5, Bilinear)
+	if v, err := reader.ElevationAtLatLon(48.5, 12.5, Bilinear); err != nil || v != expected {
+		t.Errorf("ElevationAtLatLon should return %v, but returned %v %v", expected, v, err)
+	}
+
+	reader, err = NewTileReader(bytes.NewReader(buf.Bytes()), SRTMFormat(42))
+	if reader != nil || errors.Is(err, ErrInvalidFormat) == false {
+		t.Error("NewTileReader should return an ErrInvalidFormat error, but returned", err)
+	}
+}
+
+func TestOpenTileReaderUnknownTile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "elevation.hgt")
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return 1 })
+	var buf bytes.Buffer
+	if err := img.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := OpenTileReader(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if reader.TileKnown {
+		t.Error("OpenTileReader should not know the tile of", name, "but returned", reader.Tile)
+	}
+	if _, err := reader.ElevationAtLatLon(0.5, 0.5, Bilinear); !errors.Is(err, ErrUnknownTile) {
+		t.Error("ElevationAtLatLon without tile should return an ErrUnknownTile error, but returned", err)
+	}
+}
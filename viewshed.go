@@ -0,0 +1,276 @@
+package srtm
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+)
+
+var ErrInvalidRadius = errors.New("viewshed radius must be positive and limited")
+
+// maxViewshedSamples limits the size of the grid of a viewshed, about 100 MB of memory.
+const maxViewshedSamples = 1 << 25
+
+// VisibilityOptions configure LineOfSight and Viewshed.
+type VisibilityOptions struct {
+	// ObserverHeight and TargetHeight are the heights above ground in meters,
+	// e.g. of an antenna and a receiver.
+	ObserverHeight float64
+	TargetHeight   float64
+	// Refraction is the coefficient of atmospheric refraction, which bends the line of sight
+	// along the curvature of the earth. 0 disables the correction, 1 results in a flat earth.
+	Refraction float64
+	// Interpolation is used to sample the terrain between the grid points.
+	Interpolation Interpolation
+}
+
+// DefaultVisibilityOptions places the observer at eye level and uses
+// the standard refraction coefficient of 0.13 for visible light.
+var DefaultVisibilityOptions = VisibilityOptions{ObserverHeight: 1.7, Refraction: 0.13, Interpolation: Bilinear}
+
+// curvatureDrop returns how far the surface of the earth at the given distance in meters
+// falls below the horizontal plane of the observer, reduced by the refraction.
+func curvatureDrop(distance, refraction float64) float64 {
+	return distance * distance / (2 * EarthRadius) * (1 - refraction)
+}
+
+// LineOfSight reports whether the target can be seen from the observer.
+// The terrain in between is sampled about every half grid spacing along the great circle,
+// corrected by the curvature of the earth. Voids in between do not block the sight,
+// samples on ocean tiles are at sea level.
+func (d *Dataset) LineOfSight(observer, target LatLon, options VisibilityOptions) (bool, error) {
+	elevation := func(p LatLon) (float64, error) {
+		return d.terrainElevation(p.Lat, p.Lon, options.Interpolation)
+	}
+	z0, err := elevation(observer)
+	if err != nil {
+		return false, err
+	}
+	z0 += options.ObserverHeight
+	zt, err := elevation(target)
+	if err != nil {
+		return false, err
+	}
+
+	format, err := d.formatAt(observer.Lat, observer.Lon)
+	if err != nil {
+		return false, err
+	}
+	latMeters, _ := metersPerDegree(observer.Lat)
+	step := latMeters / float64(format.Size()-1) / 2
+
+	delta := centralAngle(observer, target)
+	distance := EarthRadius * delta
+	if distance == 0 {
+		return true, nil
+	}
+	// the sight is clear, if no sample in between rises above the line to the target
+	sight := (zt + options.TargetHeight - curvatureDrop(distance, options.Refraction) - z0) / distance
+	for s := step; s < distance; s += step {
+		z, err := elevation(intermediatePoint(observer, target, delta, s/distance))
+		if errors.Is(err, ErrVoid) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if (z-curvatureDrop(s, options.Refraction)-z0)/s > sight {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Visibility classifies the samples of a Viewshed.
+type Visibility uint8
+
+const (
+	// UnknownVisibility marks samples outside of the radius or without elevation data.
+	UnknownVisibility = Visibility(iota)
+	Hidden
+	Visible
+)
+
+// Viewshed is the area visible from an observer, on a grid aligned with the samples of the dataset.
+type Viewshed struct {
+	Data   []Visibility
+	Width  int
+	Height int
+	// North and West are the coordinates of the first sample.
+	North, West float64
+	// Spacing is the distance between two samples in degrees.
+	Spacing float64
+}
+
+// Viewshed computes which samples within the radius in meters can be seen from the observer.
+// The grid has the resolution of the observer's tile and may span several tiles.
+// Missing tiles and voids are of unknown visibility and do not block the sight, ocean tiles
+// are at sea level. Radii needing a grid of more than 2^25 samples return ErrInvalidRadius.
+//
+// Sight lines are cast from the observer to every sample on the border of the grid,
+// as in the R2 algorithm of Franklin and Ray, which visits each sample a few times only.
+// A sample is visible, if the target height above it can be seen along any of the sight lines.
+func (d *Dataset) Viewshed(observer LatLon, radius float64, options VisibilityOptions) (*Viewshed, error) {
+	if !(radius > 0) || math.IsInf(radius, 0) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRadius, radius)
+	}
+	format, err := d.formatAt(observer.Lat, observer.Lon)
+	if err != nil {
+		return nil, err
+	}
+	z0, err := d.terrainElevation(observer.Lat, observer.Lon, options.Interpolation)
+	if err != nil {
+		return nil, err
+	}
+	z0 += options.ObserverHeight
+
+	spacing := 1 / float64(format.Size()-1)
+	latMeters, lonMeters := metersPerDegree(observer.Lat)
+	dx, dy := spacing*lonMeters, spacing*latMeters
+	if samples := (2*radius/dx + 1) * (2*radius/dy + 1); !(samples <= maxViewshedSamples) {
+		return nil, fmt.Errorf("%w: radius of %v m needs %.0f samples, more than %v", ErrInvalidRadius, radius, samples, maxViewshedSamples)
+	}
+	rx, ry := int(math.Ceil(radius/dx)), int(math.Ceil(radius/dy))
+	v := &Viewshed{
+		Width:   2*rx + 1,
+		Height:  2*ry + 1,
+		North:   (math.Round(observer.Lat/spacing) + float64(ry)) * spacing,
+		West:    (math.Round(observer.Lon/spacing) - float64(rx)) * spacing,
+		Spacing: spacing,
+	}
+	v.Data = make([]Visibility, v.Width*v.Height)
+
+	elevations, err := d.viewshedElevations(v)
+	if err != nil {
+		return nil, err
+	}
+	sample := func(x, y int) int16 {
+		return elevations[clamp(y, 0, v.Height-1)*v.Width+clamp(x, 0, v.Width-1)]
+	}
+
+	ox := (observer.Lon - v.West) / spacing
+	oy := (v.North - observer.Lat) / spacing
+	distance := func(x, y float64) float64 {
+		return math.Hypot((x-ox)*dx, (y-oy)*dy)
+	}
+	for i, z := range elevations {
+		if z != VoidValue && distance(float64(i%v.Width), float64(i/v.Width)) <= radius {
+			v.Data[i] = Hidden
+		}
+	}
+	if observerIndex := int(math.Round(oy))*v.Width + int(math.Round(ox)); v.Data[observerIndex] == Hidden {
+		v.Data[observerIndex] = Visible
+	}
+
+	cast := func(ex, ey int) {
+		steps := math.Max(math.Abs(float64(ex)-ox), math.Abs(float64(ey)-oy))
+		horizon := math.Inf(-1)
+		for i := 1.0; i <= math.Ceil(steps); i++ {
+			x := ox + (float64(ex)-ox)*i/steps
+			y := oy + (float64(ey)-oy)*i/steps
+			s := distance(x, y)
+			if s > radius {
+				return
+			}
+			z, err := interpolate(sample, x, y, options.Interpolation)
+			if err != nil {
+				continue
+			}
+			z -= curvatureDrop(s, options.Refraction)
+			index := int(math.Round(y))*v.Width + int(math.Round(x))
+			if v.Data[index] == Hidden && (z+options.TargetHeight-z0)/s >= horizon {
+				v.Data[index] = Visible
+			}
+			horizon = math.Max(horizon, (z-z0)/s)
+		}
+	}
+	for x := 0; x < v.Width; x++ {
+		cast(x, 0)
+		cast(x, v.Height-1)
+	}
+	for y := 1; y < v.Height-1; y++ {
+		cast(0, y)
+		cast(v.Width-1, y)
+	}
+	return v, nil
+}
+
+// viewshedElevations returns the elevations of the nearest samples of the grid of the viewshed.
+// Every tile is looked up once, missing tiles are voids and ocean tiles are at sea level.
+func (d *Dataset) viewshedElevations(v *Viewshed) ([]int16, error) {
+	images := map[Tile]*SRTMImage{}
+	ocean := map[Tile]bool{}
+	elevations := make([]int16, v.Width*v.Height)
+	for y := 0; y < v.Height; y++ {
+		for x := 0; x < v.Width; x++ {
+			i := y*v.Width + x
+			elevations[i] = VoidValue
+			lat, lon := v.PointToLatLon(image.Point{x, y})
+			if lat < -90 || lat > 90 {
+				continue
+			}
+			lon = normalizeLon(lon)
+			tile := TileAt(lat, lon)
+			img, ok := images[tile]
+			if !ok && !ocean[tile] {
+				var err error
+				img, err = d.Image(tile)
+				switch {
+				case errors.Is(err, ErrOcean):
+					ocean[tile] = true
+				case errors.Is(err, ErrTileNotFound):
+				case err != nil:
+					return nil, err
+				}
+				images[tile] = img
+			}
+			if ocean[tile] {
+				elevations[i] = 0
+			} else if img != nil {
+				px, py := img.LatLonToPoint(lat, lon)
+				elevations[i] = img.clampedAt(int(math.Round(px)), int(math.Round(py)))
+			}
+		}
+	}
+	return elevations, nil
+}
+
+// PointToLatLon converts grid coordinates to WGS84 latitude/longitude.
+func (v *Viewshed) PointToLatLon(point image.Point) (lat, lon float64) {
+	return v.North - float64(point.Y)*v.Spacing, v.West + float64(point.X)*v.Spacing
+}
+
+// At returns the visibility of the sample at the given grid coordinates.
+func (v *Viewshed) At(point image.Point) Visibility {
+	return v.Data[point.Y*v.Width+point.X]
+}
+
+// VisiblePercent returns the share of the samples within the radius that are visible,
+// ignoring samples of unknown visibility.
+func (v *Viewshed) VisiblePercent() float64 {
+	visible, known := 0, 0
+	for _, vis := range v.Data {
+		if vis != UnknownVisibility {
+			known++
+		}
+		if vis == Visible {
+			visible++
+		}
+	}
+	if known == 0 {
+		return 0
+	}
+	return float64(visible) / float64(known) * 100
+}
+
+// MaskImage returns the visible samples as a mask, which is opaque where the terrain is visible.
+func (v *Viewshed) MaskImage() *image.Alpha {
+	img := image.NewAlpha(image.Rect(0, 0, v.Width, v.Height))
+	for i, vis := range v.Data {
+		if vis == Visible {
+			img.Pix[i] = 255
+		}
+	}
+	return img
+}
@@ -0,0 +1,153 @@
+package srtm
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+// wallDataset returns a dataset of a flat tile crossed by a wall of 100 meters from north to south in its center.
+func wallDataset(t *testing.T) *Dataset {
+	dir := t.TempDir()
+	writeTestTile(t, dir, newTestImage(Tile{48, 12}, func(x, y int) int16 {
+		if x == 600 {
+			return 100
+		}
+		return 0
+	}))
+	dataset, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dataset
+}
+
+func TestDatasetLineOfSight(t *testing.T) {
+	dataset := wallDataset(t)
+	observer, target := LatLon{48.5, 12.4}, LatLon{48.5, 12.55}
+
+	options := DefaultVisibilityOptions
+	visible, err := dataset.LineOfSight(observer, target, options)
+	if err != nil || visible {
+		t.Error("the wall should hide the target, but LineOfSight returned", visible, err)
+	}
+	options.ObserverHeight = 500
+	visible, err = dataset.LineOfSight(observer, target, options)
+	if err != nil || !visible {
+		t.Error("the target should be visible above the wall, but LineOfSight returned", visible, err)
+	}
+
+	// 2 meters above the ground the horizon is about 5 km away, so two such points 20 km apart can't see each other
+	observer, target = LatLon{48.2, 12.1}, LatLon{48.2, 12.1 + 20000/74000.0}
+	options = VisibilityOptions{ObserverHeight: 2, TargetHeight: 2, Refraction: 0.13}
+	visible, err = dataset.LineOfSight(observer, target, options)
+	if err != nil || visible {
+		t.Error("the curvature of the earth should hide the target, but LineOfSight returned", visible, err)
+	}
+	options.Refraction = 1
+	visible, err = dataset.LineOfSight(observer, target, options)
+	if err != nil || !visible {
+		t.Error("the target should be visible on a flat earth, but LineOfSight returned", visible, err)
+	}
+
+	_, err = dataset.LineOfSight(observer, LatLon{50.5, 12.5}, options)
+	if !errors.Is(err, ErrTileNotFound) {
+		t.Error("LineOfSight to a missing tile should return an ErrTileNotFound error, but returned", err)
+	}
+}
+
+func TestDatasetViewshed(t *testing.T) {
+	dataset := wallDataset(t)
+	// flat terrain beyond the horizon is hidden at ground level, so look for targets 50 meters above it
+	options := DefaultVisibilityOptions
+	options.TargetHeight = 50
+	viewshed, err := dataset.Viewshed(LatLon{48.5, 12.45}, 10000, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, lonMeters := metersPerDegree(48.5)
+	if width := float64(viewshed.Width-1) * viewshed.Spacing * lonMeters; width < 20000 || width > 20000+2*lonMeters/1200 {
+		t.Error("viewshed should span the diameter of 20 km, but spanned", width)
+	}
+
+	at := func(lat, lon float64) Visibility {
+		x := int((lon-viewshed.West)/viewshed.Spacing + 0.5)
+		y := int((viewshed.North-lat)/viewshed.Spacing + 0.5)
+		return viewshed.At(image.Point{x, y})
+	}
+	if v := at(48.5, 12.4); v != Visible {
+		t.Error("terrain in front of the wall should be visible, but was", v)
+	}
+	if v := at(48.5, 12.5); v != Visible {
+		t.Error("the wall should be visible, but was", v)
+	}
+	if v := at(48.5, 12.52); v != Hidden {
+		t.Error("terrain behind the wall should be hidden, but was", v)
+	}
+	if v := at(48.58, 12.36); v != UnknownVisibility {
+		t.Error("terrain outside of the radius should be unknown, but was", v)
+	}
+
+	// the wall cuts off about a quarter of the circle
+	percent := viewshed.VisiblePercent()
+	if percent < 68 || percent > 78 {
+		t.Error("about 73 percent of the area should be visible, but were", percent)
+	}
+	mask := viewshed.MaskImage()
+	lat, lon := viewshed.PointToLatLon(image.Point{viewshed.Width / 2, viewshed.Height / 2})
+	if mask.AlphaAt(viewshed.Width/2, viewshed.Height/2).A != 255 || at(lat, lon) != Visible {
+		t.Error("the observer should be visible in the mask")
+	}
+
+	// the sight lines sample the terrain with the configured interpolation
+	for _, interpolation := range []Interpolation{NearestNeighbor, Bicubic} {
+		options.Interpolation = interpolation
+		other, err := dataset.Viewshed(LatLon{48.5, 12.45}, 10000, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if percent := other.VisiblePercent(); percent < 68 || percent > 78 {
+			t.Error(interpolation, "viewshed should see about 73 percent of the area, but saw", percent)
+		}
+	}
+	options.Interpolation = Interpolation(42)
+	if _, err := dataset.Viewshed(LatLon{48.5, 12.45}, 10000, options); err == nil {
+		t.Error("Viewshed with an unknown interpolation should return an error")
+	}
+
+	_, err = dataset.Viewshed(LatLon{48.5, 12.45}, 0, DefaultVisibilityOptions)
+	if !errors.Is(err, ErrInvalidRadius) {
+		t.Error("Viewshed without radius should return an ErrInvalidRadius error, but returned", err)
+	}
+	_, err = dataset.Viewshed(LatLon{48.5, 12.45}, 1e6, DefaultVisibilityOptions)
+	if !errors.Is(err, ErrInvalidRadius) {
+		t.Error("Viewshed with a radius of 1000 km should return an ErrInvalidRadius error, but returned", err)
+	}
+}
+
+func TestViewshedOcean(t *testing.T) {
+	dataset := wallDataset(t)
+	dataset.IsOcean = func(tile Tile) bool { return tile == Tile{48, 11} }
+
+	// a ship west of the coast sees the flat tile up to the wall
+	ship, target := LatLon{48.5, 11.98}, LatLon{48.5, 12.2}
+	options := DefaultVisibilityOptions
+	options.TargetHeight = 10
+	visible, err := dataset.LineOfSight(ship, target, options)
+	if err != nil || !visible {
+		t.Error("the target should be visible from the ship, but LineOfSight returned", visible, err)
+	}
+	viewshed, err := dataset.Viewshed(ship, 20000, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := int((target.Lon-viewshed.West)/viewshed.Spacing + 0.5)
+	y := int((viewshed.North-target.Lat)/viewshed.Spacing + 0.5)
+	if v := viewshed.At(image.Point{x, y}); v != Visible {
+		t.Error("Viewshed should agree with LineOfSight on the target, but returned", v)
+	}
+	x = int((11.9-viewshed.West)/viewshed.Spacing + 0.5)
+	if v := viewshed.At(image.Point{x, y}); v != Visible {
+		t.Error("the ocean around the ship should be visible, but was", v)
+	}
+}
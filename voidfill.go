@@ -0,0 +1,345 @@
+package srtm
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// FillMethod selects how FillVoids interpolates the elevation inside a void.
+type FillMethod int
+
+const (
+	// FillInverseDistance weights the samples surrounding a void by their inverse distance.
+	FillInverseDistance = FillMethod(iota)
+	// FillLaplacian solves the Laplace equation inside a void, resulting in the smoothest
+	// surface matching the surrounding samples.
+	FillLaplacian
+	// FillDelaunay triangulates the samples surrounding a void and interpolates linearly
+	// inside the triangles.
+	FillDelaunay
+)
+
+func (m FillMethod) String() string {
+	switch m {
+	case FillInverseDistance:
+		return "inverse distance"
+	case FillLaplacian:
+		return "laplacian"
+	case FillDelaunay:
+		return "delaunay"
+	}
+	return "invalid fill method"
+}
+
+// DefaultMaxHoleSize is the MaxHoleSize used if none is given, a void of about 100 by 100 samples.
+const DefaultMaxHoleSize = 10000
+
+// FillOptions configure FillVoids.
+type FillOptions struct {
+	Method FillMethod
+	// MaxHoleSize is the maximum number of samples of a connected void which is interpolated.
+	// Larger voids are only filled from Source. 0 uses DefaultMaxHoleSize, negative values interpolate
+	// voids of any size.
+	//
+	// Inverse distance weighting, which also starts the Laplacian and fills the voids outside of the
+	// Delaunay triangulation, weights every void by every boundary sample of its hole. A hole thus costs
+	// its number of samples times its number of boundary samples, and the Laplacian iterates over it up
+	// to 5000 times, so that a large void region of a SRTM1 tile can take minutes without a limit.
+	MaxHoleSize int
+	// Source is an optional secondary elevation model, e.g. a lower resolution tile,
+	// whose values are used before interpolating. It must cover the same area,
+	// a different grid is resampled with bilinear interpolation, which requires known tiles.
+	// A Source with the same grid as the image is copied sample by sample.
+	Source *SRTMImage
+	// Power is the exponent of the inverse distance weighting. It defaults to 2.
+	Power float64
+}
+
+// hole is a connected area of voids.
+type hole struct {
+	samples  []int // indices of the voids
+	boundary []int // indices of the valid samples surrounding the voids
+}
+
+// FillVoids returns a copy of the image with its voids filled, alongside a mask
+// in the layout of Data which is true for every filled sample.
+// Voids are copied from Source first, regardless of their size. Remaining voids which cannot be
+// interpolated, because they exceed MaxHoleSize or are not surrounded by any valid sample, keep the void value.
+func (srtmImg *SRTMImage) FillVoids(options FillOptions) (*SRTMImage, []bool, error) {
+	if options.Method < FillInverseDistance || options.Method > FillDelaunay {
+		return nil, nil, fmt.Errorf("unknown fill method: %d", options.Method)
+	}
+	if options.Power == 0 {
+		options.Power = 2
+	}
+	if options.MaxHoleSize == 0 {
+		options.MaxHoleSize = DefaultMaxHoleSize
+	}
+
+	filled := &SRTMImage{Data: make([]int16, len(srtmImg.Data)), Format: srtmImg.Format, Tile: srtmImg.Tile, TileKnown: srtmImg.TileKnown, Columns: srtmImg.Columns, Rows: srtmImg.Rows}
+	copy(filled.Data, srtmImg.Data)
+	mask := make([]bool, len(filled.Data))
+	width := filled.Width()
+
+	holes := findHoles(filled.Data, width)
+	if options.Source != nil {
+		sameGrid := filled.sameGrid(options.Source)
+		if !sameGrid {
+			if err := filled.checkTileKnown(); err != nil {
+				return nil, nil, err
+			}
+			if err := options.Source.checkTileKnown(); err != nil {
+				return nil, nil, fmt.Errorf("source: %w", err)
+			}
+		}
+		// copying is cheap, so MaxHoleSize only limits the interpolation
+		for _, h := range holes {
+			filled.fillFromSource(h, options.Source, sameGrid, mask)
+		}
+		// filling from the source may split or shrink holes
+		holes = findHoles(filled.Data, width)
+	}
+
+	for _, h := range holes {
+		if len(h.boundary) == 0 || (options.MaxHoleSize > 0 && len(h.samples) > options.MaxHoleSize) {
+			continue
+		}
+		var values []float64
+		switch options.Method {
+		case FillInverseDistance:
+			values = fillInverseDistance(filled.Data, width, h, options.Power)
+		case FillLaplacian:
+			values = fillLaplacian(filled.Data, width, h, options.Power)
+		case FillDelaunay:
+			values = fillDelaunay(filled.Data, width, h, options.Power)
+		}
+		for i, index := range h.samples {
+			filled.Data[index] = roundElevation(values[i])
+			mask[index] = true
+		}
+	}
+	return filled, mask, nil
+}
+
+// sameGrid returns true if the other image has the same samples, i.e. the same format,
+// dimensions and tile. Images whose tiles are both unknown are assumed to cover the same area.
+func (srtmImg *SRTMImage) sameGrid(other *SRTMImage) bool {
+	if other.Format != srtmImg.Format || other.Width() != srtmImg.Width() || other.Height() != srtmImg.Height() {
+		return false
+	}
+	if srtmImg.TileKnown && other.TileKnown {
+		return other.Tile == srtmImg.Tile
+	}
+	return !srtmImg.TileKnown && !other.TileKnown
+}
+
+// fillFromSource copies the non-void values of the source into the hole.
+// Unless the source has the same grid, it is resampled by latitude/longitude.
+func (srtmImg *SRTMImage) fillFromSource(h hole, source *SRTMImage, sameGrid bool, mask []bool) {
+	for _, index := range h.samples {
+		var v float64
+		if sameGrid {
+			if source.Data[index] == VoidValue {
+				continue
+			}
+			v = float64(source.Data[index])
+		} else {
+			lat, lon := srtmImg.PointToLatLon(image.Point{index % srtmImg.Width(), index / srtmImg.Width()})
+			var err error
+			v, err = source.ElevationAtLatLon(lat, lon, Bilinear)
+			if err != nil {
+				continue
+			}
+		}
+		srtmImg.Data[index] = roundElevation(v)
+		mask[index] = true
+	}
+}
+
+// findHoles returns the 4-connected areas of voids in data.
+func findHoles(data []int16, width int) []hole {
+	// stamp marks the samples visited for the current hole, avoiding a map per hole
+	stamp := make([]int32, len(data))
+	var holes []hole
+	for start, v := range data {
+		if v != VoidValue || stamp[start] != 0 {
+			continue
+		}
+		id := int32(len(holes) + 1)
+		h := hole{}
+		stamp[start] = id
+		queue := []int{start}
+		for len(queue) > 0 {
+			index := queue[0]
+			queue = queue[1:]
+			h.samples = append(h.samples, index)
+			x, y := index%width, index/width
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if (dx == 0 && dy == 0) || nx < 0 || ny < 0 || nx >= width || ny*width >= len(data) {
+						continue
+					}
+					neighbour := ny*width + nx
+					if stamp[neighbour] == id {
+						continue
+					}
+					if data[neighbour] != VoidValue {
+						// the boundary includes diagonal neighbours
+						stamp[neighbour] = id
+						h.boundary = append(h.boundary, neighbour)
+					} else if (dx == 0 || dy == 0) && stamp[neighbour] == 0 {
+						stamp[neighbour] = id
+						queue = append(queue, neighbour)
+					}
+				}
+			}
+		}
+		holes = append(holes, h)
+	}
+	return holes
+}
+
+// fillInverseDistance interpolates every void from all boundary samples of the hole.
+func fillInverseDistance(data []int16, width int, h hole, power float64) []float64 {
+	values := make([]float64, len(h.samples))
+	for i, index := range h.samples {
+		values[i] = inverseDistance(data, width, h.boundary, float64(index%width), float64(index/width), power)
+	}
+	return values
+}
+
+func inverseDistance(data []int16, width int, boundary []int, x, y, power float64) float64 {
+	var sum, weightSum float64
+	for _, b := range boundary {
+		dx, dy := float64(b%width)-x, float64(b/width)-y
+		weight := 1 / math.Pow(dx*dx+dy*dy, power/2)
+		sum += weight * float64(data[b])
+		weightSum += weight
+	}
+	return sum / weightSum
+}
+
+// fillLaplacian iteratively solves the Laplace equation inside the hole using
+// successive over-relaxation, starting from the inverse distance solution.
+// The boundary samples are fixed, the tile edges act as a reflecting border.
+func fillLaplacian(data []int16, width int, h hole, power float64) []float64 {
+	const (
+		omega         = 1.8
+		tolerance     = 0.01
+		maxIterations = 5000
+	)
+
+	values := fillInverseDistance(data, width, h, power)
+	position := make(map[int]int, len(h.samples))
+	for i, index := range h.samples {
+		position[index] = i
+	}
+
+	// precompute the 4-neighbourhood of every void, which is either another void or a fixed sample
+	type neighbours struct {
+		voids []int
+		fixed float64
+		count int
+	}
+	stencils := make([]neighbours, len(h.samples))
+	for i, index := range h.samples {
+		x, y := index%width, index/width
+		for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := x+d[0], y+d[1]
+			if nx < 0 || ny < 0 || nx >= width || ny*width >= len(data) {
+				continue
+			}
+			neighbour := ny*width + nx
+			if p, ok := position[neighbour]; ok {
+				stencils[i].voids = append(stencils[i].voids, p)
+			} else {
+				stencils[i].fixed += float64(data[neighbour])
+			}
+			stencils[i].count++
+		}
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		maxDelta := 0.0
+		for i, stencil := range stencils {
+			sum := stencil.fixed
+			for _, p := range stencil.voids {
+				sum += values[p]
+			}
+			delta := omega * (sum/float64(stencil.count) - values[i])
+			values[i] += delta
+			maxDelta = math.Max(maxDelta, math.Abs(delta))
+		}
+		if maxDelta < tolerance {
+			break
+		}
+	}
+	return values
+}
+
+// fillDelaunay triangulates the boundary samples and interpolates linearly inside the triangles.
+// Voids outside of the triangulation, e.g. at the tile edges, fall back to inverse distance weighting.
+func fillDelaunay(data []int16, width int, h hole, power float64) []float64 {
+	points := make([]point2, len(h.boundary))
+	for i, b := range h.boundary {
+		points[i] = point2{float64(b % width), float64(b / width)}
+	}
+	triangles := delaunay(points)
+
+	position := make(map[int]int, len(h.samples))
+	for i, index := range h.samples {
+		position[index] = i
+	}
+	values := make([]float64, len(h.samples))
+	done := make([]bool, len(h.samples))
+
+	for _, t := range triangles {
+		a, b, c := points[t[0]], points[t[1]], points[t[2]]
+		det := (b.y-c.y)*(a.x-c.x) + (c.x-b.x)*(a.y-c.y)
+		if det == 0 {
+			continue
+		}
+		minX, maxX := int(math.Min(a.x, math.Min(b.x, c.x))), int(math.Max(a.x, math.Max(b.x, c.x)))
+		minY, maxY := int(math.Min(a.y, math.Min(b.y, c.y))), int(math.Max(a.y, math.Max(b.y, c.y)))
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				p, ok := position[y*width+x]
+				if !ok || done[p] {
+					continue
+				}
+				// barycentric coordinates, allowing for rounding on the triangle edges
+				fx, fy := float64(x), float64(y)
+				l1 := ((b.y-c.y)*(fx-c.x) + (c.x-b.x)*(fy-c.y)) / det
+				l2 := ((c.y-a.y)*(fx-c.x) + (a.x-c.x)*(fy-c.y)) / det
+				l3 := 1 - l1 - l2
+				const epsilon = -1e-9
+				if l1 < epsilon || l2 < epsilon || l3 < epsilon {
+					continue
+				}
+				values[p] = l1*float64(data[h.boundary[t[0]]]) + l2*float64(data[h.boundary[t[1]]]) + l3*float64(data[h.boundary[t[2]]])
+				done[p] = true
+			}
+		}
+	}
+
+	for i, index := range h.samples {
+		if !done[i] {
+			values[i] = inverseDistance(data, width, h.boundary, float64(index%width), float64(index/width), power)
+		}
+	}
+	return values
+}
+
+// roundElevation rounds the value to the nearest valid elevation, never producing the void value.
+func roundElevation(v float64) int16 {
+	v = math.Round(v)
+	if v < -32767 {
+		return -32767
+	}
+	if v > 32767 {
+		return 32767
+	}
+	return int16(v)
+}
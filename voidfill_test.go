@@ -0,0 +1,189 @@
+package srtm
+
+import (
+	"testing"
+)
+
+// punchHole sets the square of samples with the given corner and width to voids.
+func punchHole(img *SRTMImage, x0, y0, width int) {
+	size := img.Format.Size()
+	for y := y0; y < y0+width; y++ {
+		for x := x0; x < x0+width; x++ {
+			img.Data[y*size+x] = VoidValue
+		}
+	}
+}
+
+func TestFillVoidsPlane(t *testing.T) {
+	plane := func(x, y int) int16 { return int16(x + 2*y) }
+
+	for _, method := range []FillMethod{FillInverseDistance, FillLaplacian, FillDelaunay} {
+		img := newTestImage(Tile{}, plane)
+		punchHole(img, 100, 200, 10)
+
+		filled, mask, err := img.FillVoids(FillOptions{Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filled.ElevationVoids()) != 0 {
+			t.Errorf("%v: FillVoids should fill all voids", method)
+		}
+		if len(img.ElevationVoids()) != 100 {
+			t.Errorf("%v: FillVoids should not modify the original image", method)
+		}
+
+		count := 0
+		for i, synthesized := range mask {
+			if synthesized {
+				count++
+			}
+			point, _ := IndexToCoordinates(i, filled.Format)
+			expected := plane(point.X, point.Y)
+			diff := filled.Data[i] - expected
+			// inverse distance weighting flattens the surface, the others reproduce planes
+			tolerance := int16(1)
+			if method == FillInverseDistance {
+				tolerance = 5
+			}
+			if diff > tolerance || diff < -tolerance {
+				t.Errorf("%v: filled value at %v should be close to %v, but was %v", method, point, expected, filled.Data[i])
+				break
+			}
+		}
+		if count != 100 {
+			t.Errorf("%v: mask should mark 100 synthesized samples, but marked %v", method, count)
+		}
+	}
+}
+
+func TestFillVoidsMaxHoleSize(t *testing.T) {
+	img := newTestImage(Tile{}, func(x, y int) int16 { return 100 })
+	punchHole(img, 10, 10, 2)
+	punchHole(img, 500, 500, 20)
+
+	filled, mask, err := img.FillVoids(FillOptions{Method: FillLaplacian, MaxHoleSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voids := len(filled.ElevationVoids()); voids != 400 {
+		t.Error("FillVoids should leave the large hole of 400 samples, but left", voids)
+	}
+	if filled.Data[10*1201+10] != 100 || !mask[10*1201+10] {
+		t.Error("FillVoids should fill the small hole")
+	}
+	if mask[500*1201+500] {
+		t.Error("mask should not mark unfilled voids")
+	}
+}
+
+func TestFillVoidsSource(t *testing.T) {
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return 100 })
+	punchHole(img, 10, 10, 4)
+	source := newTestImage(Tile{48, 12}, func(x, y int) int16 { return 200 })
+	// the source has a void of its own, which must be interpolated
+	source.Data[11*1201+11] = VoidValue
+
+	filled, mask, err := img.FillVoids(FillOptions{Method: FillInverseDistance, Source: source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled.Data[10*1201+10] != 200 || !mask[10*1201+10] {
+		t.Error("FillVoids should use the source, but filled", filled.Data[10*1201+10])
+	}
+	if filled.Data[11*1201+11] != 200 || !mask[11*1201+11] {
+		t.Error("FillVoids should interpolate voids of the source, but filled", filled.Data[11*1201+11])
+	}
+}
+
+func TestDelaunay(t *testing.T) {
+	points := []point2{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0.5, 0.4}}
+	if triangles := delaunay(points); len(triangles) != 4 {
+		t.Error("delaunay should return 4 triangles, but returned", triangles)
+	}
+	if triangles := delaunay([]point2{{0, 0}, {1, 0}, {2, 0}}); len(triangles) != 0 {
+		t.Error("delaunay of collinear points should return no triangles, but returned", triangles)
+	}
+}
+
+func TestFillVoidsDefaultMaxHoleSize(t *testing.T) {
+	img := newTestImage(Tile{}, func(x, y int) int16 { return 100 })
+	punchHole(img, 500, 500, 101)
+
+	filled, _, err := img.FillVoids(FillOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voids := len(filled.ElevationVoids()); voids != 101*101 {
+		t.Error("FillVoids should leave holes larger than DefaultMaxHoleSize by default, but left", voids, "voids")
+	}
+	filled, _, err = img.FillVoids(FillOptions{MaxHoleSize: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voids := len(filled.ElevationVoids()); voids != 0 {
+		t.Error("FillVoids with a negative MaxHoleSize should fill holes of any size, but left", voids, "voids")
+	}
+
+	// copying from the source is not limited by MaxHoleSize
+	punchHole(img, 200, 200, 200)
+	source := newTestImage(Tile{}, func(x, y int) int16 { return 50 })
+	filled, mask, err := img.FillVoids(FillOptions{Source: source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voids := len(filled.ElevationVoids()); voids != 0 || !mask[300*1201+300] || filled.Data[300*1201+300] != 50 {
+		t.Error("FillVoids should copy holes of any size from the source, but left", voids, "voids")
+	}
+}
+
+func TestDelaunayRectangularBoundary(t *testing.T) {
+	// the boundary of a rectangular hole: collinear sides and cocircular corners
+	const w, h = 12, 7
+	var points []point2
+	for x := 0; x <= w; x++ {
+		points = append(points, point2{float64(x), 0}, point2{float64(x), h})
+	}
+	for y := 1; y < h; y++ {
+		points = append(points, point2{0, float64(y)}, point2{w, float64(y)})
+	}
+
+	triangles := delaunay(points)
+	area := 0.0
+	for _, tri := range triangles {
+		a, b, c := points[tri[0]], points[tri[1]], points[tri[2]]
+		signed := orient(a, b, c)
+		if signed <= 0 {
+			t.Fatal("triangles should be counterclockwise and not degenerate, but were", a, b, c)
+		}
+		area += ((b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)) / 2
+		for i, p := range points {
+			if i != tri[0] && i != tri[1] && i != tri[2] && inCircle(a, b, c, p) > 0 {
+				t.Fatal("no point should lie inside of the circumcircle of", a, b, c, "but", p, "did")
+			}
+		}
+	}
+	// triangles which neither overlap nor leave gaps cover the rectangle exactly
+	if area != w*h {
+		t.Error("triangles should cover the area of", w*h, "but covered", area)
+	}
+
+	// a plane is interpolated exactly inside of a rectangular hole
+	plane := func(x, y int) int16 { return int16(3*x - 2*y) }
+	img := newTestImage(Tile{}, plane)
+	for y := 300; y < 307; y++ {
+		for x := 100; x < 140; x++ {
+			img.Data[y*1201+x] = VoidValue
+		}
+	}
+	filled, _, err := img.FillVoids(FillOptions{Method: FillDelaunay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 300; y < 307; y++ {
+		for x := 100; x < 140; x++ {
+			if v := filled.Data[y*1201+x]; v != plane(x, y) {
+				t.Fatal("filled value at", x, y, "should be", plane(x, y), "but was", v)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package srtm
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+// Watershed is the catchment draining through a pour point.
+type Watershed struct {
+	// PourPoint is the sample the catchment drains through, after snapping.
+	PourPoint image.Point
+	// Mask is set for every sample of the catchment, in the layout of the elevation data.
+	Mask   []bool
+	Width  int
+	Height int
+	// Area is the surface of the catchment in km².
+	Area float64
+	// Boundary is the outline of the catchment along the edges of its samples, in fractional
+	// sample coordinates. The first ring is the outer boundary, clockwise as seen on the image,
+	// the remaining rings are holes, e.g. around voids. Rings end with their first point.
+	Boundary [][]PointF
+}
+
+// Watershed delineates the catchment upstream of the given WGS84 latitude/longitude.
+// As gauge stations are rarely located exactly on the modelled streams, the pour point is snapped
+// to the sample with the highest accumulation within snapRadius meters. A snapRadius of 0 disables snapping.
+func (srtmImg *SRTMImage) Watershed(flow *FlowDirections, accumulation *Float32Grid, lat, lon, snapRadius float64) (*Watershed, error) {
+	fx, fy, err := srtmImg.latLonToPointInBounds(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	width, height := srtmImg.Width(), srtmImg.Height()
+	pour := image.Point{int(math.Round(fx)), int(math.Round(fy))}
+
+	if snapRadius > 0 {
+		latMeters, lonMeters := metersPerDegree(lat)
+		dx, dy := lonMeters/float64(srtmImg.Format.Size()-1), latMeters/float64(srtmImg.Format.Size()-1)
+		rx, ry := int(math.Ceil(snapRadius/dx)), int(math.Ceil(snapRadius/dy))
+		best := accumulation.At(pour)
+		bestDistance := 0.0
+		for y := pour.Y - ry; y <= pour.Y+ry; y++ {
+			for x := pour.X - rx; x <= pour.X+rx; x++ {
+				if x < 0 || y < 0 || x >= width || y >= height {
+					continue
+				}
+				distance := math.Hypot(float64(x)-fx, float64(y)-fy)
+				if math.Hypot((float64(x)-fx)*dx, (float64(y)-fy)*dy) > snapRadius {
+					continue
+				}
+				// prefer the closest sample among the ones with equal accumulation
+				a := accumulation.Data[y*width+x]
+				if a > best || math.IsNaN(float64(best)) || (a == best && distance < bestDistance) {
+					best, bestDistance = a, distance
+					pour = image.Point{x, y}
+				}
+			}
+		}
+	}
+	if srtmImg.Data[pour.Y*width+pour.X] == VoidValue {
+		return nil, fmt.Errorf("%w: pour point lat: %v, lon: %v", ErrVoid, lat, lon)
+	}
+
+	ws := &Watershed{PourPoint: pour, Mask: make([]bool, len(srtmImg.Data)), Width: width, Height: height}
+	start := pour.Y*width + pour.X
+	ws.Mask[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		x, y := i%width, i/width
+		for _, n := range d8 {
+			nx, ny := x+n.dx, y+n.dy
+			if nx < 0 || ny < 0 || nx >= width || ny >= height {
+				continue
+			}
+			j := ny*width + nx
+			if !ws.Mask[j] && flow.downstream(j) == i {
+				ws.Mask[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	spacing := 1 / float64(srtmImg.Format.Size()-1)
+	for y := 0; y < height; y++ {
+		rowLat, _ := srtmImg.PointToLatLon(image.Point{0, y})
+		latMeters, lonMeters := metersPerDegree(rowLat)
+		cellArea := spacing * latMeters * spacing * lonMeters / 1e6
+		for x := 0; x < width; x++ {
+			if ws.Mask[y*width+x] {
+				ws.Area += cellArea
+			}
+		}
+	}
+	ws.Boundary = traceMask(ws.Mask, width, height)
+	return ws, nil
+}
+
+// maskEdge is an edge of a sample on the boundary of a mask, between two sample corners.
+// Corner x,y is the top left corner of sample x,y.
+type maskEdge struct {
+	from, to image.Point
+	sample   int
+	used     bool
+}
+
+// traceMask returns the rings around the samples of the mask, largest first.
+// Each edge is directed clockwise around its sample, so the mask is on the right of every ring.
+// Samples touching diagonally are joined into one ring, as the D8 flow connects them.
+func traceMask(mask []bool, width, height int) [][]PointF {
+	set := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < width && y < height && mask[y*width+x]
+	}
+	var edges []maskEdge
+	for i, m := range mask {
+		if !m {
+			continue
+		}
+		x, y := i%width, i/width
+		if !set(x, y-1) {
+			edges = append(edges, maskEdge{from: image.Point{x, y}, to: image.Point{x + 1, y}, sample: i})
+		}
+		if !set(x+1, y) {
+			edges = append(edges, maskEdge{from: image.Point{x + 1, y}, to: image.Point{x + 1, y + 1}, sample: i})
+		}
+		if !set(x, y+1) {
+			edges = append(edges, maskEdge{from: image.Point{x + 1, y + 1}, to: image.Point{x, y + 1}, sample: i})
+		}
+		if !set(x-1, y) {
+			edges = append(edges, maskEdge{from: image.Point{x, y + 1}, to: image.Point{x, y}, sample: i})
+		}
+	}
+	byCorner := make(map[image.Point][]int, len(edges))
+	for i, e := range edges {
+		byCorner[e.from] = append(byCorner[e.from], i)
+	}
+
+	var rings [][]PointF
+	var areas []float64
+	for first := range edges {
+		if edges[first].used {
+			continue
+		}
+		var ring []image.Point
+		current := first
+		for {
+			edges[current].used = true
+			ring = append(ring, edges[current].from)
+			// where two samples touch diagonally, continue along the other sample
+			next := -1
+			for _, candidate := range byCorner[edges[current].to] {
+				if edges[candidate].used && candidate != first {
+					continue
+				}
+				if next < 0 || edges[candidate].sample != edges[current].sample {
+					next = candidate
+				}
+			}
+			if next < 0 || next == first {
+				break
+			}
+			current = next
+		}
+
+		// drop the corners along straight edges
+		var points []PointF
+		area := 0.0
+		for i, p := range ring {
+			prev, next := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+			area += float64(p.X*next.Y - next.X*p.Y)
+			if (p.X-prev.X)*(next.Y-p.Y) == (p.Y-prev.Y)*(next.X-p.X) {
+				continue
+			}
+			points = append(points, PointF{float64(p.X) - 0.5, float64(p.Y) - 0.5})
+		}
+		points = append(points, points[0])
+		rings = append(rings, points)
+		areas = append(areas, math.Abs(area))
+	}
+
+	// the outer boundary encloses the largest area
+	for i := range rings {
+		if areas[i] > areas[0] {
+			rings[0], rings[i] = rings[i], rings[0]
+			areas[0], areas[i] = areas[i], areas[0]
+		}
+	}
+	return rings
+}
+
+// MaskImage returns the catchment as a mask, which is opaque inside of the catchment.
+func (ws *Watershed) MaskImage() *image.Alpha {
+	img := image.NewAlpha(image.Rect(0, 0, ws.Width, ws.Height))
+	for i, m := range ws.Mask {
+		if m {
+			img.Pix[i] = 255
+		}
+	}
+	return img
+}
+
+// WriteWatershedGeoJSON writes the boundary of the catchment as a GeoJSON Polygon feature
+// in WGS84 longitude/latitude, with the properties area in km² and the pour point.
+// The outer ring is counter-clockwise and holes are clockwise, as required by RFC 7946.
+// Images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) WriteWatershedGeoJSON(w io.Writer, ws *Watershed) error {
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+	coordinates := make([][][2]float64, len(ws.Boundary))
+	for i, ring := range ws.Boundary {
+		// the image is north up, so its clockwise rings are clockwise on the map as well
+		coordinates[i], _ = srtmImg.lonLatCoordinates(ring)
+		for a, b := 0, len(ring)-1; a < b; a, b = a+1, b-1 {
+			coordinates[i][a], coordinates[i][b] = coordinates[i][b], coordinates[i][a]
+		}
+	}
+	lat, lon := srtmImg.PointToLatLon(ws.PourPoint)
+	return writeGeoJSON(w, []geoJSONFeature{{
+		Type:     "Feature",
+		Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: coordinates},
+		Properties: map[string]interface{}{
+			"area":      ws.Area,
+			"pourPoint": [2]float64{roundDecimals(lon, 7), roundDecimals(lat, 7)},
+		},
+	}})
+}
@@ -0,0 +1,113 @@
+package srtm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestWatershed(t *testing.T) {
+	// a valley falling towards the west, its eastern half drains through the center of the tile
+	img := newTestImage(Tile{48, 12}, func(x, y int) int16 { return int16(x + 20*absInt(y-600)) })
+	flow, err := img.FlowDirections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accumulation := img.FlowAccumulation(flow)
+
+	// about 280 meters north of the valley, the snap radius reaches the valley at the same longitude only
+	lat, lon := img.FractionalPointToLatLon(600, 597)
+	ws, err := img.Watershed(flow, accumulation, lat, lon, 280)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.PourPoint != (image.Point{600, 600}) {
+		t.Error("the pour point should snap to the valley at 600,600, but was", ws.PourPoint)
+	}
+	count := 0
+	for _, m := range ws.Mask {
+		if m {
+			count++
+		}
+	}
+	if count != 601*1201 {
+		t.Error("the catchment should cover the eastern half of the tile, but had", count, "samples")
+	}
+	// a tile spans about 111.2 x 73.8 km at this latitude
+	if area := 111.2 * 73.8 / 2; math.Abs(ws.Area-area)/area > 0.01 {
+		t.Error("the catchment should cover about", area, "km², but covered", ws.Area)
+	}
+	expected := []PointF{{599.5, -0.5}, {1200.5, -0.5}, {1200.5, 1200.5}, {599.5, 1200.5}, {599.5, -0.5}}
+	if len(ws.Boundary) != 1 || len(ws.Boundary[0]) != len(expected) {
+		t.Fatal("the boundary should be a rectangle, but was", ws.Boundary)
+	}
+	for i, p := range ws.Boundary[0] {
+		if p != expected[i] {
+			t.Error("the boundary should be", expected, "but was", ws.Boundary[0])
+			break
+		}
+	}
+	if mask := ws.MaskImage(); mask.AlphaAt(700, 100).A != 255 || mask.AlphaAt(500, 100).A != 0 {
+		t.Error("the mask should be opaque in the catchment only")
+	}
+
+	// without snapping the catchment north of the valley is a single column
+	ws, err = img.Watershed(flow, accumulation, lat, lon, 0)
+	if err != nil || ws.PourPoint != (image.Point{600, 597}) {
+		t.Fatal("the pour point should not be snapped, but was", ws.PourPoint, err)
+	}
+
+	var buf bytes.Buffer
+	if err := img.WriteWatershedGeoJSON(&buf, ws); err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates [][][2]float64
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil || collection.Features[0].Geometry.Type != "Polygon" {
+		t.Fatal("GeoJSON should contain a Polygon, but was", err, buf.String())
+	}
+	// the shoelace formula is positive for counter-clockwise rings
+	ring := collection.Features[0].Geometry.Coordinates[0]
+	area := 0.0
+	for i := 0; i+1 < len(ring); i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	if area <= 0 {
+		t.Error("the outer ring should be counter-clockwise, but was", ring)
+	}
+
+	_, err = img.Watershed(flow, accumulation, 50, 12, 0)
+	if !errors.Is(err, ErrLatLonOutOfBounds) {
+		t.Error("Watershed outside of the tile should return an ErrLatLonOutOfBounds error, but returned", err)
+	}
+}
+
+func TestTraceMask(t *testing.T) {
+	// two samples touching diagonally and a ring of samples with a hole
+	mask := make([]bool, 8*8)
+	for _, p := range []image.Point{{0, 0}, {1, 1}, {3, 3}, {4, 3}, {5, 3}, {3, 4}, {5, 4}, {3, 5}, {4, 5}, {5, 5}} {
+		mask[p.Y*8+p.X] = true
+	}
+	rings := traceMask(mask, 8, 8)
+	if len(rings) != 3 {
+		t.Fatal("traceMask should return three rings, but returned", rings)
+	}
+	if len(rings[0]) != 5 {
+		t.Error("the largest ring should be the outline of the ring of samples, but was", rings[0])
+	}
+	for _, ring := range rings[1:] {
+		// the diagonal pair is an outline of eight corners, the hole a square
+		if len(ring) != 9 && len(ring) != 5 {
+			t.Error("traceMask should join diagonal samples and keep the hole, but returned", ring)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package srtm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidXYZ = errors.New("invalid XYZ file")
+
+// EncodeXYZ writes the elevation data as XYZ points, one "longitude latitude elevation" line
+// per sample from north to south and west to east. Voids are left out,
+// images with an unknown tile return ErrUnknownTile.
+func (srtmImg *SRTMImage) EncodeXYZ(w io.Writer) error {
+	if size := srtmImg.Format.Size(); size < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidFormat, srtmImg.Format)
+	}
+	width, height := srtmImg.Width(), srtmImg.Height()
+	// larger mosaics could not be read again
+	if err := checkMosaicSize(srtmImg.Format, atLeastOne(srtmImg.Columns), atLeastOne(srtmImg.Rows)); err != nil {
+		return err
+	}
+	if len(srtmImg.Data) != width*height {
+		return fmt.Errorf("%w: %v, length: %v", ErrInvalidDataLength, srtmImg.Format, len(srtmImg.Data))
+	}
+	if err := srtmImg.checkTileKnown(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var line []byte
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := srtmImg.Data[y*width+x]
+			if v == VoidValue {
+				continue
+			}
+			lat, lon := srtmImg.PointToLatLon(image.Point{x, y})
+			line = strconv.AppendFloat(line[:0], lon, 'f', -1, 64)
+			line = append(line, ' ')
+			line = strconv.AppendFloat(line, lat, 'f', -1, 64)
+			line = append(line, ' ')
+			line = strconv.AppendInt(line, int64(v), 10)
+			line = append(line, '\n')
+			if _, err := bw.Write(line); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodeXYZ reads "longitude latitude elevation" points in WGS84 coordinates on a regular grid,
+// separated by white space, commas or semicolons. A header line and lines starting with # are skipped.
+// The spacing of the grid is derived from the coordinates, missing points are voids.
+// Grids exceeding MaxMosaicSamples are rejected.
+//
+// Like DecodeGeoTIFF, the elevations are resampled onto the SRTM grid of the tiles the points cover,
+// points written by EncodeXYZ are read exactly.
+func DecodeXYZ(r io.Reader) (*SRTMImage, error) {
+	type point struct {
+		lon, lat  float64
+		elevation int16
+	}
+	var points []point
+	// the distinct coordinates determine the grid
+	lons, lats := map[float64]bool{}, map[float64]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ';' || r == ' ' || r == '\t'
+		})
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%w: line %v: %v fields instead of 3", ErrInvalidXYZ, line, len(fields))
+		}
+		var values [3]float64
+		var err error
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if len(points) == 0 && line == 1 {
+				// header, e.g. "X Y Z"
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %v: %v", ErrInvalidXYZ, line, err)
+		}
+		if values[0] < -180 || values[0] > 180 || values[1] < -90 || values[1] > 90 {
+			return nil, fmt.Errorf("%w: line %v: %v/%v is not in WGS84 coordinates", ErrInvalidXYZ, line, values[1], values[0])
+		}
+		points = append(points, point{values[0], values[1], rasterElevation(values[2], math.NaN())})
+		lons[values[0]] = true
+		lats[values[1]] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w: no points", ErrInvalidXYZ)
+	}
+
+	west, scaleX, width := gridAxis(lons)
+	south, scaleY, height := gridAxis(lats)
+	if scaleX == 0 {
+		scaleX = scaleY
+	}
+	if scaleY == 0 {
+		scaleY = scaleX
+	}
+	if scaleX == 0 {
+		return nil, fmt.Errorf("%w: a single point does not define a grid", ErrInvalidXYZ)
+	}
+
+	if err := checkRasterSize(width, height, ErrInvalidXYZ); err != nil {
+		return nil, err
+	}
+	g := &raster{width: width, height: height, west: west, north: south + float64(height-1)*scaleY, scaleX: scaleX, scaleY: scaleY}
+	g.data = make([]int16, width*height)
+	for i := range g.data {
+		g.data[i] = VoidValue
+	}
+	for _, p := range points {
+		fx, fy := (p.lon-g.west)/scaleX, (g.north-p.lat)/scaleY
+		x, y := math.Round(fx), math.Round(fy)
+		if math.Abs(fx-x) > 0.01 || math.Abs(fy-y) > 0.01 {
+			return nil, fmt.Errorf("%w: %v/%v is not on a regular grid", ErrInvalidXYZ, p.lat, p.lon)
+		}
+		g.data[int(y)*width+int(x)] = p.elevation
+	}
+	return g.resample(ErrInvalidXYZ)
+}
+
+// gridAxis returns the first coordinate, spacing and number of coordinates of a regular grid
+// along one axis from its distinct coordinates. The spacing is the smallest difference between
+// the coordinates, averaged over the whole axis to reduce the rounding of the coordinates.
+func gridAxis(coordinates map[float64]bool) (first, spacing float64, n int) {
+	sorted := make([]float64, 0, len(coordinates))
+	for c := range coordinates {
+		sorted = append(sorted, c)
+	}
+	sort.Float64s(sorted)
+
+	const epsilon = 1e-9
+	first, last := sorted[0], sorted[len(sorted)-1]
+	smallest := math.Inf(1)
+	for i := 1; i < len(sorted); i++ {
+		if d := sorted[i] - sorted[i-1]; d > epsilon && d < smallest {
+			smallest = d
+		}
+	}
+	if math.IsInf(smallest, 1) {
+		return first, 0, 1
+	}
+	steps := math.Round((last - first) / smallest)
+	if steps > maxRasterSamples {
+		// too large a grid, rejected by the caller
+		steps = maxRasterSamples
+	}
+	return first, (last - first) / steps, int(steps) + 1
+}
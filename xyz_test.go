@@ -0,0 +1,72 @@
+package srtm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeXYZ(t *testing.T) {
+	img := newTestImage(Tile{-1, -1}, func(x, y int) int16 { return int16(x + y) })
+	// voids on the edge shrink the grid of the points
+	for x := 0; x < 1201; x++ {
+		img.Data[x] = VoidValue
+	}
+	img.Data[1201*5+7] = VoidValue
+
+	var buf bytes.Buffer
+	if err := img.EncodeXYZ(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if line := "-1 -0.0008333333333333334 1\n"; !strings.HasPrefix(buf.String(), line) {
+		t.Error("XYZ should start with", line, "but started with", buf.String()[:len(line)])
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 1201*1200-1 {
+		t.Error("XYZ should hold a line per valid sample, but held", lines)
+	}
+
+	decoded, err := DecodeXYZ(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalImages(img, decoded) || decoded.IsMosaic() {
+		t.Error("XYZ should decode to the encoded image, but returned", decoded.Format, decoded.Tile)
+	}
+}
+
+func TestDecodeXYZ(t *testing.T) {
+	// points with a spacing of 1 arc second, one of them missing
+	points := "X,Y,Z\n# comment\n" +
+		"12,48.5,500.4\n12.000277777777777,48.5,501\n12.000555555555556,48.5,502\n" +
+		"12,48.49972222222222,510\n12.000555555555556,48.49972222222222,512.5\n"
+	img, err := DecodeXYZ(strings.NewReader(points))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format != SRTM1Format || img.Tile != (Tile{48, 12}) {
+		t.Fatal("XYZ should cover the SRTM1 tile N48E012, but returned", img.Format, img.Tile)
+	}
+	x, y := img.LatLonToPoint(48.5, 12)
+	if x != 0 || y != 1800 {
+		t.Fatal("48.5/12 should be at 0/1800, but was", x, y)
+	}
+	for _, p := range []struct {
+		x, y      int
+		elevation int16
+	}{{0, 1800, 500}, {2, 1800, 502}, {0, 1801, 510}, {1, 1801, VoidValue}, {2, 1801, 513}, {3, 1800, VoidValue}} {
+		if e := img.Data[p.y*img.Width()+p.x]; e != p.elevation {
+			t.Error("elevation at", p.x, p.y, "should be", p.elevation, "but was", e)
+		}
+	}
+
+	for _, invalid := range []string{"", "12 48\n", "12 48 1\n12.1 48 1\n12.25 48 1\n", "12 48 1\nx y z\n", "500000 5300000 1\n500030 5300000 1\n",
+		// a stray point would need a grid of 10^7 x 10^7 samples
+		"12 48 1\n12.0000001 48.0000001 1\n13 49 1\n",
+		// points far apart cover too many tiles
+		"-170 -60 1\n170 60 1\n"} {
+		if _, err := DecodeXYZ(strings.NewReader(invalid)); !errors.Is(err, ErrInvalidXYZ) {
+			t.Errorf("%q should return an ErrInvalidXYZ error, but returned %v", invalid, err)
+		}
+	}
+}